@@ -7,10 +7,15 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/arkeonetwork/common/logging"
 	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/health"
+	"github.com/arkeonetwork/directory/pkg/metrics"
+	"github.com/arkeonetwork/directory/pkg/tracing"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type ApiService struct {
@@ -22,7 +27,12 @@ type ApiService struct {
 type ApiServiceParams struct {
 	ListenAddr string
 	StaticDir  string
-	DBConfig   db.DBConfig
+	// readiness fails when the latest indexed block is older
+	MaxBlockAge time.Duration
+	// prefixes used to validate and convert between account pubkeys and addresses
+	Bech32PrefixAccAddr string
+	Bech32PrefixAccPub  string
+	DBConfig            db.DBConfig
 }
 
 const DefaultListenAddress = "localhost:7777"
@@ -59,9 +69,25 @@ func (a *ApiService) start(doneChan chan struct{}) {
 
 func buildRouter(a *ApiService) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(metrics.HTTPMiddleware, tracing.HTTPMiddleware)
+	router.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	checker := a.healthChecker()
 	router.HandleFunc("/health", handleHealth).Methods(http.MethodGet)
+	router.HandleFunc("/health/live", health.LiveHandler).Methods(http.MethodGet)
+	router.HandleFunc("/health/ready", checker.ReadyHandler).Methods(http.MethodGet)
 	router.HandleFunc("/stats", a.getStatsArkeo).Methods(http.MethodGet)
 	router.HandleFunc("/stats/{chain}", getStatsChain).Methods(http.MethodGet)
+	router.HandleFunc("/quote", a.getQuote).Methods(http.MethodGet)
+	router.HandleFunc("/blocks/latest", a.getLatestBlock).Methods(http.MethodGet)
+	router.HandleFunc("/blocks/at", a.getBlockAt).Methods(http.MethodGet)
+	router.HandleFunc("/blocks/{height:[0-9]+}", a.getBlock).Methods(http.MethodGet)
+	router.HandleFunc("/indexer/status", a.getIndexerStatus).Methods(http.MethodGet)
+	router.HandleFunc("/reconcile", a.getReconcileReport).Methods(http.MethodGet)
+	router.HandleFunc("/chains", a.getChains).Methods(http.MethodGet)
+	router.HandleFunc("/validators", a.getValidators).Methods(http.MethodGet)
+	router.HandleFunc("/validators/{addr}/payouts", a.getValidatorPayouts).Methods(http.MethodGet)
+	router.HandleFunc("/fees/{role}", a.getFeesByRole).Methods(http.MethodGet)
+	router.HandleFunc("/txs/failed", a.getFailedTxs).Methods(http.MethodGet)
 
 	if a.params.StaticDir == "" {
 		log.Warnf("API_STATIC_DIR not set, using ./auto_static")
@@ -73,9 +99,18 @@ func buildRouter(a *ApiService) *mux.Router {
 	router.PathPrefix("/docs").Handler(http.StripPrefix("/docs", fileServer))
 
 	providerRouter := router.PathPrefix("/provider").Subrouter()
+	// registered ahead of /{pubkey}, which would otherwise match it
+	providerRouter.HandleFunc("/recommend", a.recommendProviders).Methods(http.MethodGet)
 	providerRouter.HandleFunc("/{pubkey}", a.getProvider).Methods(http.MethodGet)
+	providerRouter.HandleFunc("/{pubkey}/metadata-status", a.getProviderMetadataStatus).Methods(http.MethodGet)
+	providerRouter.HandleFunc("/{pubkey}/reputation", a.getProviderReputation).Methods(http.MethodGet)
+	providerRouter.HandleFunc("/{pubkey}/contracts", a.getProviderContracts).Methods(http.MethodGet)
+	providerRouter.HandleFunc("/{pubkey}/fees", a.getProviderFees).Methods(http.MethodGet)
 	providerRouter.HandleFunc("/search/", a.searchProviders).Methods(http.MethodGet)
 
+	router.HandleFunc("/client/{key}/contracts", a.getClientContracts).Methods(http.MethodGet)
+	router.HandleFunc("/client/{key}/fees", a.getClientFees).Methods(http.MethodGet)
+
 	// router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
 	// 	tpl, _ := route.GetPathTemplate()
 	// 	log.Infof("walk: %s", tpl)
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/gorilla/mux"
+)
+
+// swagger:route Get /blocks/latest getLatestBlock
+//
+// Get the latest indexed block
+//
+// Responses:
+//
+//	200: Block
+//	404: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getLatestBlock(w http.ResponseWriter, r *http.Request) {
+	block, err := a.db.WithContext(r.Context()).FindLatestBlock()
+	if err != nil {
+		log.Errorf("error finding latest block: %+v", err)
+		respondWithError(w, http.StatusInternalServerError, "error finding latest block")
+		return
+	}
+	if block == nil {
+		respondWithError(w, http.StatusNotFound, "no blocks indexed")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, block)
+}
+
+// swagger:route Get /blocks/{height} getBlock
+//
+// Get an indexed block by height
+//
+// Parameters:
+//   + name: height
+//     in: path
+//     description: block height
+//     required: true
+//     type: integer
+//
+// Responses:
+//
+//	200: Block
+//	404: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getBlock(w http.ResponseWriter, r *http.Request) {
+	height, err := strconv.ParseInt(mux.Vars(r)["height"], 10, 64)
+	if err != nil || height <= 0 {
+		respondWithError(w, http.StatusBadRequest, "height must be a positive integer")
+		return
+	}
+	block, err := a.db.WithContext(r.Context()).FindBlock(height)
+	if err != nil {
+		log.Errorf("error finding block %d: %+v", height, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding block %d", height))
+		return
+	}
+	if block == nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("block %d not indexed", height))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, block)
+}
+
+// swagger:model BlockTime
+type BlockTime struct {
+	Height int64
+	Time   time.Time
+	// false when the block is stored, true when extrapolated from the average block time
+	Estimated bool
+	// seconds per block
+	AvgBlockTime float64
+}
+
+// swagger:route Get /blocks/at getBlockAt
+//
+// Convert between block height and wall-clock time. stored blocks are used where available, other heights and times
+// are estimated from the rolling average block time
+//
+// Parameters:
+//   + name: time
+//     in: query
+//     description: RFC3339 timestamp to find the height at, exclusive with height
+//     required: false
+//     type: string
+//   + name: height
+//     in: query
+//     description: height to find the time of, exclusive with time
+//     required: false
+//     type: integer
+//
+// Responses:
+//
+//	200: BlockTime
+//	400: InternalServerError
+//	503: InternalServerError
+
+func (a *ApiService) getBlockAt(w http.ResponseWriter, r *http.Request) {
+	timeInput := r.FormValue("time")
+	heightInput := r.FormValue("height")
+	if (timeInput == "") == (heightInput == "") {
+		respondWithError(w, http.StatusBadRequest, "exactly one of time or height is required")
+		return
+	}
+
+	clock, err := a.db.WithContext(r.Context()).FindBlockClock()
+	if err != nil {
+		log.Errorf("error finding block clock: %+v", err)
+		respondWithError(w, http.StatusInternalServerError, "error finding block time")
+		return
+	}
+	if clock == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "average block time not yet available")
+		return
+	}
+
+	var result *BlockTime
+	if timeInput != "" {
+		t, err := time.Parse(time.RFC3339, timeInput)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "time must be RFC3339")
+			return
+		}
+		result, err = a.heightAtTime(r.Context(), clock, t)
+		if err != nil {
+			log.Errorf("error finding height at %s: %+v", t, err)
+			respondWithError(w, http.StatusInternalServerError, "error finding height")
+			return
+		}
+	} else {
+		height, err := strconv.ParseInt(heightInput, 10, 64)
+		if err != nil || height <= 0 {
+			respondWithError(w, http.StatusBadRequest, "height must be a positive integer")
+			return
+		}
+		result, err = a.timeAtHeight(r.Context(), clock, height)
+		if err != nil {
+			log.Errorf("error finding time at %d: %+v", height, err)
+			respondWithError(w, http.StatusInternalServerError, "error finding time")
+			return
+		}
+	}
+	result.AvgBlockTime = clock.AvgBlockTime
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// times after the latest block are extrapolated, earlier ones resolve to the last stored block at or before them
+func (a *ApiService) heightAtTime(ctx context.Context, clock *db.BlockClock, t time.Time) (*BlockTime, error) {
+	if t.Before(clock.RefTime) || t.Equal(clock.RefTime) {
+		block, err := a.db.WithContext(ctx).FindBlockAtTime(t)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			return &BlockTime{Height: block.Height, Time: block.BlockTime}, nil
+		}
+	}
+	height := clock.HeightAt(t)
+	return &BlockTime{Height: height, Time: clock.TimeAt(height), Estimated: true}, nil
+}
+
+func (a *ApiService) timeAtHeight(ctx context.Context, clock *db.BlockClock, height int64) (*BlockTime, error) {
+	if height <= clock.RefHeight {
+		block, err := a.db.WithContext(ctx).FindBlock(height)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			return &BlockTime{Height: block.Height, Time: block.BlockTime}, nil
+		}
+	}
+	return &BlockTime{Height: height, Time: clock.TimeAt(height), Estimated: true}, nil
+}
+
+// fill in provider ages in seconds. they're an estimate, so are left nil rather than failing the request when
+// the average block time isn't available
+func (a *ApiService) setAgeSeconds(ctx context.Context, providers ...*db.ArkeoProvider) {
+	clock, err := a.db.WithContext(ctx).FindBlockClock()
+	if err != nil {
+		log.Errorf("error finding block clock: %+v", err)
+		return
+	}
+	if clock == nil {
+		return
+	}
+	for _, p := range providers {
+		if p == nil {
+			continue
+		}
+		seconds := clock.Duration(p.Age).Seconds()
+		p.AgeSeconds = &seconds
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/arkeonetwork/directory/pkg/chains"
+)
+
+// swagger:route Get /chains getChains
+//
+// List the registered chains with their provider and contract counts
+//
+// Responses:
+//
+//	200: []ChainSummary
+//	500: InternalServerError
+
+func (a *ApiService) getChains(w http.ResponseWriter, r *http.Request) {
+	results, err := a.db.WithContext(r.Context()).FindChains()
+	if err != nil {
+		log.Errorf("error finding chains: %+v", err)
+		respondWithError(w, http.StatusInternalServerError, "error finding chains")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+// validateChain checks chain is in the registry, responding with an error and returning false when it isn't
+func (a *ApiService) validateChain(w http.ResponseWriter, r *http.Request, chain string) bool {
+	if !chains.ValidName(chain) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("%s is not a valid chain", chain))
+		return false
+	}
+	c, err := a.db.WithContext(r.Context()).FindChain(chain)
+	if err != nil {
+		log.Errorf("error finding chain %s: %+v", chain, err)
+		respondWithError(w, http.StatusInternalServerError, "error finding chain")
+		return false
+	}
+	if c == nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("%s is not a valid chain", chain))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/gorilla/mux"
+)
+
+// a contract with its remaining blocks and wall-clock estimates, which are nil until the average block time is known
+type ContractResponse struct {
+	*db.ArkeoContract
+	Remaining        int64
+	OpenedAt         *time.Time
+	ExpiresAt        *time.Time
+	RemainingSeconds *float64
+}
+
+// swagger:model ContractResponses
+type ContractResponses []*ContractResponse
+
+// swagger:route Get /provider/{pubkey}/contracts getProviderContracts
+//
+// Get a provider's contracts, newest first, with estimated open and expiry times
+//
+// Parameters:
+//   + name: pubkey
+//     in: path
+//     description: provider public key or account address
+//     required: true
+//     type: string
+//   + name: chain
+//	   in: query
+//     description: chain identifier
+//     required: true
+//     type: string
+//
+// Responses:
+//
+//	200: ContractResponses
+//	500: InternalServerError
+
+func (a *ApiService) getProviderContracts(w http.ResponseWriter, r *http.Request) {
+	pubkey := mux.Vars(r)["pubkey"]
+	chain := r.FormValue("chain")
+	if pubkey == "" {
+		respondWithError(w, http.StatusBadRequest, "pubkey is required")
+		return
+	}
+	if chain == "" {
+		respondWithError(w, http.StatusBadRequest, "chain is required")
+		return
+	}
+	pubkey, ok := a.resolveProviderPubkey(w, r, pubkey)
+	if !ok {
+		return
+	}
+	contracts, err := a.db.WithContext(r.Context()).FindProviderContracts(pubkey, chain)
+	if err != nil {
+		log.Errorf("error finding contracts for %s chain %s: %+v", pubkey, chain, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding contracts for pubkey %s", pubkey))
+		return
+	}
+	head, clock, ok := a.contractClock(w, r)
+	if !ok {
+		return
+	}
+
+	results := make(ContractResponses, 0, len(contracts))
+	for _, c := range contracts {
+		results = append(results, contractResponse(c, head, clock))
+	}
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+// contractClock finds the indexed head contracts' remaining blocks are counted from and the block clock, nil until
+// the average block time is known. returns false after responding with 500 on a lookup failure
+func (a *ApiService) contractClock(w http.ResponseWriter, r *http.Request) (int64, *db.BlockClock, bool) {
+	d := a.db.WithContext(r.Context())
+	latest, err := d.FindLatestBlock()
+	if err != nil {
+		log.Errorf("error finding latest block: %+v", err)
+		respondWithError(w, http.StatusInternalServerError, "error finding latest block")
+		return 0, nil, false
+	}
+	clock, err := d.FindBlockClock()
+	if err != nil {
+		log.Errorf("error finding block clock: %+v", err)
+		respondWithError(w, http.StatusInternalServerError, "error finding block time")
+		return 0, nil, false
+	}
+	var head int64
+	if latest != nil {
+		head = latest.Height
+	}
+	return head, clock, true
+}
+
+// a contract expires at height + duration unless closed before then. remaining blocks are counted from the indexed
+// head, the times need the block clock
+func contractResponse(c *db.ProviderContract, head int64, clock *db.BlockClock) *ContractResponse {
+	resp := &ContractResponse{ArkeoContract: &c.ArkeoContract, OpenedAt: c.OpenedBlockTime}
+	expiresHeight := c.Height + c.Duration
+	if c.ClosedHeight > 0 && c.ClosedHeight < expiresHeight {
+		expiresHeight = c.ClosedHeight
+	}
+	if remaining := expiresHeight - head; head > 0 && remaining > 0 {
+		resp.Remaining = remaining
+	}
+	if clock == nil {
+		return resp
+	}
+	if resp.OpenedAt == nil {
+		openedAt := clock.TimeAt(c.Height)
+		resp.OpenedAt = &openedAt
+	}
+	expiresAt := clock.TimeAt(expiresHeight)
+	resp.ExpiresAt = &expiresAt
+	remainingSeconds := clock.Duration(resp.Remaining).Seconds()
+	resp.RemainingSeconds = &remainingSeconds
+	return resp
+}
+
+// a client's contract with the provider serving it
+type ClientContractResponse struct {
+	*ContractResponse
+	ProviderPubkey  string
+	ProviderAddress string
+	Chain           string
+}
+
+// swagger:model ClientContractResponses
+type ClientContractResponses []*ClientContractResponse
+
+// swagger:route Get /client/{key}/contracts getClientContracts
+//
+// Get the contracts a client is party to, as client or delegate, newest first
+//
+// Parameters:
+//   + name: key
+//     in: path
+//     description: client public key or account address
+//     required: true
+//     type: string
+//
+// Responses:
+//
+//	200: ClientContractResponses
+//	400: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getClientContracts(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	pubkey, ok := a.resolvePubkey(w, r, key)
+	if !ok {
+		return
+	}
+	results := make(ClientContractResponses, 0)
+	if pubkey == "" {
+		// an address no contract is indexed under has no contracts
+		respondWithJSON(w, http.StatusOK, results)
+		return
+	}
+	contracts, err := a.db.WithContext(r.Context()).FindClientContracts(pubkey)
+	if err != nil {
+		log.Errorf("error finding contracts for client %s: %+v", pubkey, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding contracts for client %s", key))
+		return
+	}
+	head, clock, ok := a.contractClock(w, r)
+	if !ok {
+		return
+	}
+
+	for _, c := range contracts {
+		results = append(results, &ClientContractResponse{
+			ContractResponse: contractResponse(&db.ProviderContract{ArkeoContract: c.ArkeoContract}, head, clock),
+			ProviderPubkey:   c.ProviderPubkey,
+			ProviderAddress:  c.ProviderAddress,
+			Chain:            c.Chain,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, results)
+}
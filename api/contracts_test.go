@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+)
+
+func TestContractResponse(t *testing.T) {
+	open := &db.ProviderContract{ArkeoContract: db.ArkeoContract{Height: 100, Duration: 50}}
+	closed := &db.ProviderContract{ArkeoContract: db.ArkeoContract{Height: 100, Duration: 50, ClosedHeight: 120}}
+
+	// remaining blocks don't need the block clock
+	resp := contractResponse(open, 130, nil)
+	if resp.Remaining != 20 || resp.ExpiresAt != nil || resp.RemainingSeconds != nil {
+		t.Errorf("unexpected response without a clock %+v", resp)
+	}
+	if resp = contractResponse(closed, 130, nil); resp.Remaining != 0 {
+		t.Errorf("expected no blocks remaining of a closed contract got %d", resp.Remaining)
+	}
+
+	ref := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &db.BlockClock{RefHeight: 130, RefTime: ref, AvgBlockTime: 5}
+	resp = contractResponse(open, 130, clock)
+	if resp.Remaining != 20 || *resp.RemainingSeconds != 100 || !resp.ExpiresAt.Equal(ref.Add(100*time.Second)) {
+		t.Errorf("unexpected response with a clock %+v", resp)
+	}
+}
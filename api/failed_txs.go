@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/utils"
+)
+
+const (
+	defaultFailedTxLimit = 100
+	maxFailedTxLimit     = 1000
+)
+
+// swagger:model FailedTxs
+type FailedTxs []*db.FailedTx
+
+// swagger:route Get /txs/failed getFailedTxs
+//
+// List the arkeo messages of failed txs with their error, most recent first
+//
+// Parameters:
+//   + name: signer
+//     in: query
+//     description: public key or account address that signed or executed the message
+//     required: false
+//     type: string
+//   + name: provider
+//     in: query
+//     description: public key or account address of the provider the message names
+//     required: false
+//     type: string
+//   + name: limit
+//     in: query
+//     description: number of messages to return, defaults to 100, at most 1000
+//     required: false
+//     type: integer
+//
+// Responses:
+//
+//	200: FailedTxs
+//	400: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getFailedTxs(w http.ResponseWriter, r *http.Request) {
+	signer := ""
+	if key := r.FormValue("signer"); key != "" {
+		parsed, err := utils.ParseAccountKey(key, a.params.Bech32PrefixAccPub, a.params.Bech32PrefixAccAddr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid pubkey or address %s: %s", key, err))
+			return
+		}
+		signer = parsed.Address
+	}
+	provider := ""
+	if key := r.FormValue("provider"); key != "" {
+		var ok bool
+		if provider, ok = a.resolvePubkey(w, r, key); !ok {
+			return
+		}
+		if provider == "" {
+			respondWithJSON(w, http.StatusOK, FailedTxs{})
+			return
+		}
+	}
+	limit := defaultFailedTxLimit
+	if limitInput := r.FormValue("limit"); limitInput != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitInput); err != nil || limit < 1 || limit > maxFailedTxLimit {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxFailedTxLimit))
+			return
+		}
+	}
+
+	results, err := a.db.WithContext(r.Context()).FindFailedTxs(signer, provider, limit)
+	if err != nil {
+		log.Errorf("error finding failed txs of signer %s provider %s: %+v", signer, provider, err)
+		respondWithError(w, http.StatusInternalServerError, "error finding failed txs")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, FailedTxs(results))
+}
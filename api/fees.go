@@ -0,0 +1,108 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/utils"
+	"github.com/gorilla/mux"
+)
+
+// swagger:model AccountFeesList
+type AccountFeesList []*db.AccountFees
+
+// swagger:route Get /provider/{pubkey}/fees getProviderFees
+//
+// Get the fees and gas a provider has paid for its arkeo txs
+//
+// Parameters:
+//   + name: pubkey
+//     in: path
+//     description: provider public key or account address
+//     required: true
+//     type: string
+//
+// Responses:
+//
+//	200: AccountFees
+//	400: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getProviderFees(w http.ResponseWriter, r *http.Request) {
+	a.respondWithAccountFees(w, r, mux.Vars(r)["pubkey"])
+}
+
+// swagger:route Get /client/{key}/fees getClientFees
+//
+// Get the fees and gas a client has paid for its arkeo txs
+//
+// Parameters:
+//   + name: key
+//     in: path
+//     description: client public key or account address
+//     required: true
+//     type: string
+//
+// Responses:
+//
+//	200: AccountFees
+//	400: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getClientFees(w http.ResponseWriter, r *http.Request) {
+	a.respondWithAccountFees(w, r, mux.Vars(r)["key"])
+}
+
+// fees are paid by an account so both forms of key total the same txs
+func (a *ApiService) respondWithAccountFees(w http.ResponseWriter, r *http.Request, key string) {
+	parsed, err := utils.ParseAccountKey(key, a.params.Bech32PrefixAccPub, a.params.Bech32PrefixAccAddr)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid pubkey or address %s: %s", key, err))
+		return
+	}
+	fees, err := a.db.WithContext(r.Context()).FindAccountFees(parsed.Address)
+	if err != nil {
+		log.Errorf("error finding fees of %s: %+v", parsed.Address, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding fees of %s", key))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, fees)
+}
+
+// swagger:route Get /fees/{role} getFeesByRole
+//
+// List the fees and gas paid by each provider or client, most fees first
+//
+// Parameters:
+//   + name: role
+//     in: path
+//     description: providers or clients
+//     required: true
+//     type: string
+//
+// Responses:
+//
+//	200: AccountFeesList
+//	400: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getFeesByRole(w http.ResponseWriter, r *http.Request) {
+	var role db.FeeRole
+	switch mux.Vars(r)["role"] {
+	case "providers":
+		role = db.FeeRoleProvider
+	case "clients":
+		role = db.FeeRoleClient
+	default:
+		respondWithError(w, http.StatusBadRequest, "role must be providers or clients")
+		return
+	}
+	results, err := a.db.WithContext(r.Context()).FindFeesByRole(role)
+	if err != nil {
+		log.Errorf("error finding %s fees: %+v", role, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding %s fees", role))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, AccountFeesList(results))
+}
@@ -1,11 +1,48 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/health"
+)
+
+// DefaultMaxBlockAge is how old the latest indexed block may be before the api reports itself not ready
+const DefaultMaxBlockAge = 5 * time.Minute
 
 type Health struct {
 	Overall string
 }
 
+// the original health route, kept answering as before for existing probes. /health/ready checks dependencies
 func handleHealth(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, Health{Overall: "LGTM"})
 }
+
+// swagger:route Get /health/live getHealthLive
+//
+// Liveness, the process is up and serving
+//
+// Responses:
+//
+//	200: HealthReport
+
+// swagger:route Get /health/ready getHealthReady
+//
+// Readiness, checks the db pool, schema version and indexer freshness
+//
+// Responses:
+//
+//	200: HealthReport
+//	503: HealthReport
+
+func (a *ApiService) healthChecker() *health.Checker {
+	maxBlockAge := a.params.MaxBlockAge
+	if maxBlockAge <= 0 {
+		maxBlockAge = DefaultMaxBlockAge
+	}
+	return health.NewChecker(health.DefaultTimeout).
+		Add("db", health.DBCheck(a.db)).
+		Add("schema", health.SchemaCheck(a.db)).
+		Add("indexer", health.FreshnessCheck(a.db, maxBlockAge))
+}
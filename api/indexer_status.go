@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+)
+
+// gaps listed in the status response, GapCount and MissingBlocks cover all of them
+const statusMaxGaps = 100
+
+// swagger:model IndexerStatusResponse
+type IndexerStatusResponse struct {
+	LatestHeight    int64
+	LatestBlockTime *time.Time
+	// height blocks are indexed through, the one before the first gap
+	IndexedHeight int64
+	// latest height seen on the chain and how far IndexedHeight is behind it, the indexer's lag metric, nil until
+	// the indexer reports a tip
+	ChainTip        *int64
+	ChainTipUpdated *time.Time
+	Lag             *int64
+	// blocks missing below LatestHeight
+	GapCount      int
+	MissingBlocks int64
+	Gaps          []*db.BlockGap
+	GapFill       GapFillProgress
+	// indexed events by type
+	EventCounts map[string]int64
+}
+
+// progress of the current, or if Running is false the last, gap fill run
+type GapFillProgress struct {
+	Running  bool
+	Blocks   int64
+	Done     int64
+	Progress float64
+	Started  *time.Time
+	Finished *time.Time
+}
+
+// swagger:route Get /indexer/status getIndexerStatus
+//
+// Get indexing progress, lag behind the chain and outstanding gaps
+//
+// Responses:
+//
+//	200: IndexerStatusResponse
+//	500: InternalServerError
+
+func (a *ApiService) getIndexerStatus(w http.ResponseWriter, r *http.Request) {
+	resp, err := a.indexerStatus(r.Context())
+	if err != nil {
+		log.Errorf("error finding indexer status: %+v", err)
+		respondWithError(w, http.StatusInternalServerError, "error finding indexer status")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (a *ApiService) indexerStatus(ctx context.Context) (*IndexerStatusResponse, error) {
+	resp := &IndexerStatusResponse{Gaps: []*db.BlockGap{}, EventCounts: map[string]int64{}}
+
+	latest, err := a.db.WithContext(ctx).FindLatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		resp.LatestHeight = latest.Height
+		resp.LatestBlockTime = &latest.BlockTime
+	}
+
+	status, err := a.db.WithContext(ctx).FindLatestIndexerStatus()
+	if err != nil {
+		return nil, err
+	}
+	if status != nil {
+		resp.IndexedHeight = int64(status.Height)
+		resp.ChainTip, resp.ChainTipUpdated = status.ChainTip, status.ChainTipUpdated
+		if status.ChainTip != nil {
+			lag := *status.ChainTip - resp.IndexedHeight
+			if lag < 0 {
+				lag = 0
+			}
+			resp.Lag = &lag
+		}
+		resp.GapFill = GapFillProgress{
+			Running:  status.GapFillStarted != nil && status.GapFillFinished == nil,
+			Blocks:   status.GapFillBlocks,
+			Done:     status.GapFillDone,
+			Started:  status.GapFillStarted,
+			Finished: status.GapFillFinished,
+		}
+		if status.GapFillBlocks > 0 {
+			resp.GapFill.Progress = float64(status.GapFillDone) / float64(status.GapFillBlocks)
+		}
+	}
+
+	gaps, err := a.db.WithContext(ctx).FindBlockGaps()
+	if err != nil {
+		return nil, err
+	}
+	resp.GapCount = len(gaps)
+	for i, g := range gaps {
+		resp.MissingBlocks += g.End - g.Start + 1
+		if i < statusMaxGaps {
+			resp.Gaps = append(resp.Gaps, g)
+		}
+	}
+
+	counts, err := a.db.WithContext(ctx).FindEventCounts()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		resp.EventCounts[c.EventType] = c.Count
+	}
+	return resp, nil
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/arkeonetwork/directory/pkg/utils"
+)
+
+// resolvePubkey accepts a bech32 account pubkey or account address and returns the pubkey it identifies. an address
+// is looked up among the indexed pubkeys, an empty pubkey is returned when no indexed pubkey derives to it. returns
+// false after responding with 400 on a malformed key or 500 on a lookup failure
+func (a *ApiService) resolvePubkey(w http.ResponseWriter, r *http.Request, key string) (string, bool) {
+	parsed, err := utils.ParseAccountKey(key, a.params.Bech32PrefixAccPub, a.params.Bech32PrefixAccAddr)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid pubkey or address %s: %s", key, err))
+		return "", false
+	}
+	if parsed.Pubkey != "" {
+		return parsed.Pubkey, true
+	}
+	pubkey, err := a.db.WithContext(r.Context()).FindPubkeyByAddress(parsed.Address)
+	if err != nil {
+		log.Errorf("error finding pubkey for address %s: %+v", parsed.Address, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding pubkey for address %s", parsed.Address))
+		return "", false
+	}
+	return pubkey, true
+}
+
+// resolveProviderPubkey resolves the {pubkey} path variable of the provider routes, responding 404 for an address
+// no provider is indexed under
+func (a *ApiService) resolveProviderPubkey(w http.ResponseWriter, r *http.Request, key string) (string, bool) {
+	pubkey, ok := a.resolvePubkey(w, r, key)
+	if !ok {
+		return "", false
+	}
+	if pubkey == "" {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("no provider found for address %s", key))
+		return "", false
+	}
+	return pubkey, true
+}
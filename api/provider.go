@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -30,7 +31,7 @@ type ArkeoProviders []*db.ArkeoProvider
 // Parameters:
 //   + name: pubkey
 //     in: path
-//     description: provider public key
+//     description: provider public key or account address
 //     required: true
 //     type: string
 //   + name: chain
@@ -56,8 +57,12 @@ func (a *ApiService) getProvider(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, http.StatusBadRequest, "chain is required")
 		return
 	}
+	pubkey, ok := a.resolveProviderPubkey(w, r, pubkey)
+	if !ok {
+		return
+	}
 	// "bitcoin-mainnet"
-	provider, err := a.findProvider(pubkey, chain)
+	provider, err := a.findProvider(r.Context(), pubkey, chain)
 	if err != nil {
 		log.Errorf("error finding provider for %s chain %s: %+v", pubkey, chain, err)
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding provider with pubkey %s", pubkey))
@@ -67,15 +72,68 @@ func (a *ApiService) getProvider(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, provider)
 }
 
+// swagger:route Get /provider/{pubkey}/metadata-status getProviderMetadataStatus
+//
+// Get the fetch status of a provider's metadata document
+//
+// Parameters:
+//   + name: pubkey
+//     in: path
+//     description: provider public key or account address
+//     required: true
+//     type: string
+//   + name: chain
+//	   in: query
+//     description: chain identifier
+//     required: true
+//     type: string
+//
+// Responses:
+//
+//	200: ProviderMetadataStatus
+//	404: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getProviderMetadataStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pubkey := vars["pubkey"]
+	chain := r.FormValue("chain")
+	if pubkey == "" {
+		respondWithError(w, http.StatusBadRequest, "pubkey is required")
+		return
+	}
+	if chain == "" {
+		respondWithError(w, http.StatusBadRequest, "chain is required")
+		return
+	}
+	pubkey, ok := a.resolveProviderPubkey(w, r, pubkey)
+	if !ok {
+		return
+	}
+	status, err := a.db.WithContext(r.Context()).FindMetadataStatus(pubkey, chain)
+	if err != nil {
+		log.Errorf("error finding metadata status for %s chain %s: %+v", pubkey, chain, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding metadata status for pubkey %s", pubkey))
+		return
+	}
+	if status == nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("no metadata status for pubkey %s chain %s", pubkey, chain))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, status)
+}
+
 // find a provider by pubkey+chain
-func (a *ApiService) findProvider(pubkey, chain string) (*db.ArkeoProvider, error) {
-	dbProvider, err := a.db.FindProvider(pubkey, chain)
+func (a *ApiService) findProvider(ctx context.Context, pubkey, chain string) (*db.ArkeoProvider, error) {
+	dbProvider, err := a.db.WithContext(ctx).FindProvider(pubkey, chain)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error finding provider for %s %s", pubkey, chain)
 	}
 	if dbProvider == nil {
 		return nil, nil
 	}
+	a.setAgeSeconds(ctx, dbProvider)
 
 	// provider := &db.ArkeoProvider{Pubkey: dbProvider.Pubkey}
 	return dbProvider, nil
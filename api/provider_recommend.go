@@ -0,0 +1,166 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/arkeonetwork/directory/pkg/utils"
+	"github.com/gorilla/mux"
+)
+
+const (
+	recommendLimit = 10
+	// distance at which a provider's reputation counts for half
+	recommendHalfDistanceKm = 2000.0
+)
+
+// swagger:model ProviderRecommendations
+type ProviderRecommendations []*db.ProviderRecommendation
+
+// swagger:route Get /provider/recommend recommendProviders
+//
+// Recommend online providers for a chain, ranked by reputation and, when coordinates are given, proximity
+//
+// Parameters:
+//   + name: chain
+//     in: query
+//     description: chain identifier
+//     required: true
+//     type: string
+//   + name: coordinates
+//	   description: latitude and longitude of the client (example 40.7127837,-74.0059413)
+//     in: query
+//     required: false
+//     type: string
+//   + name: tier
+//	   description: only providers offering this tier of service
+//     in: query
+//     required: false
+//     schema:
+//      type: string
+//      enum: free, subscription, paygo
+//
+// Responses:
+//
+//	200: ProviderRecommendations
+//	400: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) recommendProviders(w http.ResponseWriter, r *http.Request) {
+	chain := r.FormValue("chain")
+	coordinatesInput := r.FormValue("coordinates")
+	tier := r.FormValue("tier")
+
+	if chain == "" {
+		respondWithError(w, http.StatusBadRequest, "chain is required")
+		return
+	}
+	if !a.validateChain(w, r, chain) {
+		return
+	}
+	params := types.ProviderRecommendParams{Chain: chain}
+
+	switch types.ServiceTier(tier) {
+	case types.ServiceTierNone, types.ServiceTierFree, types.ServiceTierSubscription, types.ServiceTierPayAsYouGo:
+		params.Tier = types.ServiceTier(tier)
+	default:
+		respondWithError(w, http.StatusBadRequest, "tier can not be parsed")
+		return
+	}
+
+	if coordinatesInput != "" {
+		coordinates, err := utils.ParseCoordinates(coordinatesInput)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "coordinates can not be parsed")
+			return
+		}
+		params.Coordinates = coordinates
+		params.IsCoordinatesSet = true
+	}
+
+	candidates, err := a.db.WithContext(r.Context()).FindRecommendationCandidates(params)
+	if err != nil {
+		log.Errorf("error finding recommendation candidates for chain %s: %+v", chain, err)
+		respondWithError(w, http.StatusInternalServerError, "error recommending providers")
+		return
+	}
+
+	recommendations := rankRecommendations(candidates, recommendLimit)
+	providers := make([]*db.ArkeoProvider, 0, len(recommendations))
+	for _, rec := range recommendations {
+		providers = append(providers, &rec.ArkeoProvider)
+	}
+	a.setAgeSeconds(r.Context(), providers...)
+	respondWithJSON(w, http.StatusOK, recommendations)
+}
+
+// order by reputation discounted by distance, keeping the best limit
+func rankRecommendations(candidates []*db.ProviderRecommendation, limit int) []*db.ProviderRecommendation {
+	for _, c := range candidates {
+		c.Rank = c.Reputation.Score
+		if c.DistanceKm != nil {
+			c.Rank = c.Reputation.Score / (1 + *c.DistanceKm/recommendHalfDistanceKm)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Rank > candidates[j].Rank
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// swagger:route Get /provider/{pubkey}/reputation getProviderReputation
+//
+// Get the reputation score of a provider with its component breakdown
+//
+// Parameters:
+//   + name: pubkey
+//     in: path
+//     description: provider public key or account address
+//     required: true
+//     type: string
+//   + name: chain
+//	   in: query
+//     description: chain identifier
+//     required: true
+//     type: string
+//
+// Responses:
+//
+//	200: ProviderReputation
+//	404: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getProviderReputation(w http.ResponseWriter, r *http.Request) {
+	pubkey := mux.Vars(r)["pubkey"]
+	chain := r.FormValue("chain")
+	if pubkey == "" {
+		respondWithError(w, http.StatusBadRequest, "pubkey is required")
+		return
+	}
+	if chain == "" {
+		respondWithError(w, http.StatusBadRequest, "chain is required")
+		return
+	}
+	pubkey, ok := a.resolveProviderPubkey(w, r, pubkey)
+	if !ok {
+		return
+	}
+	reputation, err := a.db.WithContext(r.Context()).FindProviderReputation(pubkey, chain)
+	if err != nil {
+		log.Errorf("error finding reputation for %s chain %s: %+v", pubkey, chain, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding reputation for pubkey %s", pubkey))
+		return
+	}
+	if reputation == nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("no reputation for pubkey %s chain %s", pubkey, chain))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reputation)
+}
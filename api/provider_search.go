@@ -1,7 +1,6 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -22,7 +21,7 @@ import (
 //      type: string
 //   + name: pubkey
 //     in: query
-//     description: pubkey of provider
+//     description: pubkey or account address of provider
 //     required: false
 //     schema:
 //      type: string
@@ -32,7 +31,7 @@ import (
 //     required: false
 //     schema:
 //      type: string
-//      enum: age, conract_count, amount_paid
+//      enum: age, conract_count, amount_paid, uptime, latency, reputation
 //   + name: max-distance
 //     in: query
 //     description: maximum distance in kilometers from provided coordinates
@@ -44,7 +43,7 @@ import (
 //     required: false
 //     type: string
 //   + name: min-validator-payments
-//	   description: minimum amount the provider has paid to validators
+//	   description: minimum amount paid out to the validator operated with the provider's key
 //     in: query
 //     required: false
 //	   type: integer
@@ -73,6 +72,21 @@ import (
 //     in: query
 //     required: false
 //	   type: integer
+//   + name: min-uptime
+//	   description: minimum fraction (0-1) of successful probes of the provider's sentinel over the last 24 hours
+//     in: query
+//     required: false
+//	   type: number
+//   + name: max-latency
+//	   description: maximum median latency in milliseconds of the provider's sentinel over the last 24 hours
+//     in: query
+//     required: false
+//	   type: integer
+//   + name: verified
+//	   description: only providers whose metadata is (true) or is not (false) signed by the provider's key
+//     in: query
+//     required: false
+//	   type: boolean
 // Responses:
 //
 //	200: ArkeoProviders
@@ -91,6 +105,9 @@ func (a *ApiService) searchProviders(response http.ResponseWriter, request *http
 	minPaygoRateLimitInput := request.FormValue("min-payasyougo-rate-limit")
 	minSubscribeRateLimitInput := request.FormValue("min-subscription-rate-limit")
 	minOpenContractsInput := request.FormValue("min-open-contracts")
+	verifiedInput := request.FormValue("verified")
+	minUptimeInput := request.FormValue("min-uptime")
+	maxLatencyInput := request.FormValue("max-latency")
 
 	if (maxDistanceInput != "" && coordinatesInput == "") || (coordinatesInput != "" && maxDistanceInput == "") {
 		respondWithError(response, http.StatusBadRequest, "max distance must accompany coordinates when supplied")
@@ -107,15 +124,32 @@ func (a *ApiService) searchProviders(response http.ResponseWriter, request *http
 		searchParams.SortKey = types.ProviderSortKeyAmountPaid
 	case string(types.ProviderSortKeyContractCount):
 		searchParams.SortKey = types.ProviderSortKeyContractCount
+	case string(types.ProviderSortKeyUptime):
+		searchParams.SortKey = types.ProviderSortKeyUptime
+	case string(types.ProviderSortKeyLatency):
+		searchParams.SortKey = types.ProviderSortKeyLatency
+	case string(types.ProviderSortKeyReputation):
+		searchParams.SortKey = types.ProviderSortKeyReputation
 	default:
 		respondWithError(response, http.StatusBadRequest, "sort key can not be parsed")
 		return
 	}
 
-	searchParams.Pubkey = pubkey
+	if pubkey != "" {
+		resolved, ok := a.resolvePubkey(response, request, pubkey)
+		if !ok {
+			return
+		}
+		if resolved == "" {
+			// an address no provider is indexed under matches nothing
+			respondWithJSON(response, http.StatusOK, ArkeoProviders{})
+			return
+		}
+		searchParams.Pubkey = resolved
+	}
 
-	if chain != "" && !utils.ValidateChain(chain) {
-		respondWithError(response, http.StatusBadRequest, fmt.Sprintf("%s is not a valid chain", chain))
+	if chain != "" && !a.validateChain(response, request, chain) {
+		return
 	}
 	searchParams.Chain = chain
 
@@ -201,11 +235,43 @@ func (a *ApiService) searchProviders(response http.ResponseWriter, request *http
 		searchParams.MinOpenContracts = minOpenContracts
 		searchParams.IsMinOpenContractsSet = true
 	}
-	results, err := a.db.SearchProviders(searchParams)
+
+	if minUptimeInput != "" {
+		minUptime, err := strconv.ParseFloat(minUptimeInput, 64)
+		if err != nil || minUptime < 0 || minUptime > 1 {
+			respondWithError(response, http.StatusBadRequest, "min-uptime must be a number between 0 and 1")
+			return
+		}
+		searchParams.MinUptime = minUptime
+		searchParams.IsMinUptimeSet = true
+	}
+
+	if maxLatencyInput != "" {
+		maxLatency, err := strconv.ParseInt(maxLatencyInput, 10, 64)
+		if err != nil {
+			respondWithError(response, http.StatusBadRequest, "max-latency can not be parsed")
+			return
+		}
+		searchParams.MaxLatency = maxLatency
+		searchParams.IsMaxLatencySet = true
+	}
+
+	if verifiedInput != "" {
+		verified, err := strconv.ParseBool(verifiedInput)
+		if err != nil {
+			respondWithError(response, http.StatusBadRequest, "verified can not be parsed")
+			return
+		}
+		searchParams.Verified = verified
+		searchParams.IsVerifiedSet = true
+	}
+	results, err := a.db.WithContext(request.Context()).SearchProviders(searchParams)
 	if err != nil {
 		log.Errorf("error searching providers: %+v", err)
 		respondWithError(response, http.StatusInternalServerError, "error searching providers")
+		return
 	}
+	a.setAgeSeconds(request.Context(), results...)
 
 	respondWithJSON(response, http.StatusOK, results)
 }
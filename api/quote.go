@@ -0,0 +1,183 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/arkeonetwork/directory/pkg/utils"
+)
+
+// price of a contract with a provider. durations are in blocks and costs in the chain's base denom
+type ProviderQuote struct {
+	Pubkey          string
+	Chain           string
+	ContractType    types.ContractType
+	Duration        int64
+	ExpectedQueries int64
+	Rate            types.Amount
+	TotalCost       types.Amount
+	// TotalCost / ExpectedQueries, nil if no queries were given for a subscription
+	EffectivePerQueryCost *float64
+	// requests allowed per RateLimitDurationSeconds for the contract's tier, nil without fetched metadata
+	RateLimit                *int64
+	RateLimitDurationSeconds *float64
+	ReputationScore          *float64
+	Uptime                   *float64
+}
+
+// a provider that can't be quoted for the requested terms
+type RejectedQuote struct {
+	Pubkey string
+	Reason string
+}
+
+// swagger:model QuoteResponse
+type QuoteResponse struct {
+	Quotes   []*ProviderQuote
+	Rejected []*RejectedQuote
+}
+
+// swagger:route Get /quote getQuote
+//
+// Price a contract with every eligible provider of a chain, cheapest first
+//
+// Parameters:
+//   + name: chain
+//     in: query
+//     description: chain identifier
+//     required: true
+//     type: string
+//   + name: type
+//     in: query
+//     description: contract type
+//     required: true
+//     schema:
+//      type: string
+//      enum: Subscription, PayAsYouGo
+//   + name: duration
+//     in: query
+//     description: contract duration in blocks
+//     required: true
+//     type: integer
+//   + name: expected-queries
+//     in: query
+//     description: number of queries expected over the contract, required for PayAsYouGo
+//     required: false
+//     type: integer
+//
+// Responses:
+//
+//	200: QuoteResponse
+//	400: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getQuote(w http.ResponseWriter, r *http.Request) {
+	chain := r.FormValue("chain")
+	contractTypeInput := r.FormValue("type")
+	durationInput := r.FormValue("duration")
+	expectedQueriesInput := r.FormValue("expected-queries")
+
+	if chain == "" {
+		respondWithError(w, http.StatusBadRequest, "chain is required")
+		return
+	}
+	if !a.validateChain(w, r, chain) {
+		return
+	}
+	contractType, err := utils.ParseContractType(contractTypeInput)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "type can not be parsed")
+		return
+	}
+	duration, err := strconv.ParseInt(durationInput, 10, 64)
+	if err != nil || duration <= 0 {
+		respondWithError(w, http.StatusBadRequest, "duration must be a positive integer")
+		return
+	}
+	var expectedQueries int64
+	if expectedQueriesInput != "" {
+		expectedQueries, err = strconv.ParseInt(expectedQueriesInput, 10, 64)
+		if err != nil || expectedQueries < 0 {
+			respondWithError(w, http.StatusBadRequest, "expected-queries must be a non negative integer")
+			return
+		}
+	}
+	if contractType == types.ContractTypePayAsYouGo && expectedQueries == 0 {
+		respondWithError(w, http.StatusBadRequest, "expected-queries is required for PayAsYouGo")
+		return
+	}
+
+	candidates, err := a.db.WithContext(r.Context()).FindQuoteCandidates(chain, contractType)
+	if err != nil {
+		log.Errorf("error finding quote candidates for chain %s: %+v", chain, err)
+		respondWithError(w, http.StatusInternalServerError, "error quoting providers")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, buildQuotes(candidates, contractType, duration, expectedQueries))
+}
+
+func buildQuotes(candidates []*db.QuoteCandidate, contractType types.ContractType, duration, expectedQueries int64) *QuoteResponse {
+	resp := &QuoteResponse{Quotes: make([]*ProviderQuote, 0, len(candidates)), Rejected: make([]*RejectedQuote, 0)}
+	for _, c := range candidates {
+		quote, err := quoteProvider(c, contractType, duration, expectedQueries)
+		if err != nil {
+			resp.Rejected = append(resp.Rejected, &RejectedQuote{Pubkey: c.Pubkey, Reason: err.Error()})
+			continue
+		}
+		resp.Quotes = append(resp.Quotes, quote)
+	}
+	sort.SliceStable(resp.Quotes, func(i, j int) bool {
+		return resp.Quotes[i].TotalCost.BigInt().Cmp(resp.Quotes[j].TotalCost.BigInt()) < 0
+	})
+	return resp
+}
+
+// a subscription costs rate per block for its duration, pay-as-you-go costs rate per query
+func quoteProvider(c *db.QuoteCandidate, contractType types.ContractType, duration, expectedQueries int64) (*ProviderQuote, error) {
+	if duration < c.MinContractDuration {
+		return nil, fmt.Errorf("duration %d below provider minimum %d", duration, c.MinContractDuration)
+	}
+	if c.MaxContractDuration > 0 && duration > c.MaxContractDuration {
+		return nil, fmt.Errorf("duration %d above provider maximum %d", duration, c.MaxContractDuration)
+	}
+
+	quote := &ProviderQuote{
+		Pubkey:          c.Pubkey,
+		Chain:           c.Chain,
+		ContractType:    contractType,
+		Duration:        duration,
+		ExpectedQueries: expectedQueries,
+		ReputationScore: c.ReputationScore,
+		Uptime:          c.Uptime,
+	}
+	units := expectedQueries
+	var rateLimitDuration *int64
+	switch contractType {
+	case types.ContractTypeSubscription:
+		quote.Rate, units = c.SubscriptionRate, duration
+		quote.RateLimit, rateLimitDuration = c.SubscribeRateLimit, c.SubscribeRateLimitDuration
+	case types.ContractTypePayAsYouGo:
+		quote.Rate = c.PayAsYouGoRate
+		quote.RateLimit, rateLimitDuration = c.PaygoRateLimit, c.PaygoRateLimitDuration
+	default:
+		return nil, fmt.Errorf("unexpected contract type %s", contractType)
+	}
+	if quote.Rate.Sign() <= 0 {
+		return nil, fmt.Errorf("provider does not offer %s contracts", contractType)
+	}
+	quote.TotalCost = quote.Rate.MulInt64(units)
+	if expectedQueries > 0 {
+		perQuery := quote.TotalCost.Float64() / float64(expectedQueries)
+		quote.EffectivePerQueryCost = &perQuery
+	}
+	if rateLimitDuration != nil {
+		seconds := float64(*rateLimitDuration) / 1e9
+		quote.RateLimitDurationSeconds = &seconds
+	}
+	return quote, nil
+}
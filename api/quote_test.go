@@ -0,0 +1,52 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/types"
+)
+
+func amt(i int64) types.Amount {
+	return types.NewAmount(i, types.DefaultDenom)
+}
+
+func TestBuildQuotes(t *testing.T) {
+	limit, limitDuration := int64(10), int64(time.Minute)
+	candidates := []*db.QuoteCandidate{
+		{Pubkey: "expensive", MinContractDuration: 10, MaxContractDuration: 1000, SubscriptionRate: amt(20), PayAsYouGoRate: amt(5)},
+		{Pubkey: "cheap", MinContractDuration: 10, MaxContractDuration: 1000, SubscriptionRate: amt(10), PayAsYouGoRate: amt(2),
+			SubscribeRateLimit: &limit, SubscribeRateLimitDuration: &limitDuration},
+		{Pubkey: "long-only", MinContractDuration: 500, MaxContractDuration: 1000, SubscriptionRate: amt(1), PayAsYouGoRate: amt(1)},
+		{Pubkey: "short-only", MinContractDuration: 1, MaxContractDuration: 50, SubscriptionRate: amt(1), PayAsYouGoRate: amt(1)},
+	}
+
+	resp := buildQuotes(candidates, types.ContractTypeSubscription, 100, 400)
+	if len(resp.Quotes) != 2 || len(resp.Rejected) != 2 {
+		t.Fatalf("expected 2 quotes and 2 rejections got %d %d", len(resp.Quotes), len(resp.Rejected))
+	}
+	cheap := resp.Quotes[0]
+	if cheap.Pubkey != "cheap" || !cheap.TotalCost.Equal(amt(1000)) {
+		t.Errorf("expected cheap provider first at 1000 got %s at %s", cheap.Pubkey, cheap.TotalCost)
+	}
+	if cheap.EffectivePerQueryCost == nil || *cheap.EffectivePerQueryCost != 2.5 {
+		t.Errorf("expected per query cost 2.5 got %v", cheap.EffectivePerQueryCost)
+	}
+	if cheap.RateLimit == nil || *cheap.RateLimit != 10 || cheap.RateLimitDurationSeconds == nil || *cheap.RateLimitDurationSeconds != 60 {
+		t.Errorf("expected rate limit 10 per 60s got %v %v", cheap.RateLimit, cheap.RateLimitDurationSeconds)
+	}
+
+	resp = buildQuotes(candidates, types.ContractTypePayAsYouGo, 600, 400)
+	if len(resp.Quotes) != 3 {
+		t.Fatalf("expected 3 quotes got %d", len(resp.Quotes))
+	}
+	if resp.Quotes[0].Pubkey != "long-only" || !resp.Quotes[0].TotalCost.Equal(amt(400)) || *resp.Quotes[0].EffectivePerQueryCost != 1 {
+		t.Errorf("unexpected cheapest pay-as-you-go quote %+v", resp.Quotes[0])
+	}
+
+	resp = buildQuotes(candidates, types.ContractTypeSubscription, 100, 0)
+	if resp.Quotes[0].EffectivePerQueryCost != nil {
+		t.Error("expected no per query cost without expected queries")
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+)
+
+// swagger:model ReconcileReport
+type ReconcileReport struct {
+	Run        *db.ReconcileRun
+	Mismatches []*db.ReconcileMismatch
+}
+
+// swagger:route Get /reconcile getReconcileReport
+//
+// Get the mismatches found by the latest reconciliation of providers and contracts against the chain
+//
+// Responses:
+//
+//	200: ReconcileReport
+//	404: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getReconcileReport(w http.ResponseWriter, r *http.Request) {
+	d := a.db.WithContext(r.Context())
+	run, err := d.FindLatestReconcileRun()
+	if err != nil {
+		log.Errorf("error finding latest reconcile run: %+v", err)
+		respondWithError(w, http.StatusInternalServerError, "error finding latest reconcile run")
+		return
+	}
+	if run == nil {
+		respondWithError(w, http.StatusNotFound, "no reconciliation has run")
+		return
+	}
+	mismatches, err := d.FindReconcileMismatches(run.ID)
+	if err != nil {
+		log.Errorf("error finding mismatches of reconcile run %d: %+v", run.ID, err)
+		respondWithError(w, http.StatusInternalServerError, "error finding mismatches")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, ReconcileReport{Run: run, Mismatches: mismatches})
+}
@@ -16,7 +16,7 @@ import (
 //	200: ArkeoStats
 //	500: InternalServerError
 func (a *ApiService) getStatsArkeo(w http.ResponseWriter, r *http.Request) {
-	arkeoStats, err := a.db.GetArkeoNetworkStats()
+	arkeoStats, err := a.db.WithContext(r.Context()).GetArkeoNetworkStats()
 	if err != nil {
 		log.Error("error finding stats for Arkeo Network")
 		respondWithError(w, http.StatusInternalServerError, "error finding stats for Arkeo Network")
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultValidatorPayoutLimit = 100
+	maxValidatorPayoutLimit     = 1000
+)
+
+// periods validator payouts can be totalled over, the date_trunc fields they correspond to
+var validatorPayoutPeriods = map[string]bool{"day": true, "week": true, "month": true}
+
+// swagger:model ValidatorPayouts
+type ValidatorPayouts struct {
+	Validator *db.ValidatorSummary
+	// totals per Period, newest first
+	Period  string
+	Periods []*db.ValidatorPayoutPeriod
+	// most recent payouts, newest first
+	Payouts []*db.ValidatorPayout
+}
+
+// swagger:route Get /validators getValidators
+//
+// List validators that have received payouts with their totals, largest total first
+//
+// Responses:
+//
+//	200: []ValidatorSummary
+//	500: InternalServerError
+
+func (a *ApiService) getValidators(w http.ResponseWriter, r *http.Request) {
+	results, err := a.db.WithContext(r.Context()).FindValidators()
+	if err != nil {
+		log.Errorf("error finding validators: %+v", err)
+		respondWithError(w, http.StatusInternalServerError, "error finding validators")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+// swagger:route Get /validators/{addr}/payouts getValidatorPayouts
+//
+// Get a validator's payouts, totalled per period and the most recent individually
+//
+// Parameters:
+//   + name: addr
+//     in: path
+//     description: validator operator address or account address
+//     required: true
+//     type: string
+//   + name: period
+//     in: query
+//     description: day, week or month, defaults to day
+//     required: false
+//     type: string
+//   + name: limit
+//     in: query
+//     description: number of recent payouts to return, defaults to 100, at most 1000
+//     required: false
+//     type: integer
+//
+// Responses:
+//
+//	200: ValidatorPayouts
+//	400: InternalServerError
+//	404: InternalServerError
+//	500: InternalServerError
+
+func (a *ApiService) getValidatorPayouts(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["addr"]
+	period := r.FormValue("period")
+	if period == "" {
+		period = "day"
+	}
+	if !validatorPayoutPeriods[period] {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("period %s must be one of day, week or month", period))
+		return
+	}
+	limit := defaultValidatorPayoutLimit
+	if limitInput := r.FormValue("limit"); limitInput != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitInput); err != nil || limit < 1 || limit > maxValidatorPayoutLimit {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxValidatorPayoutLimit))
+			return
+		}
+	}
+
+	d := a.db.WithContext(r.Context())
+	validator, err := d.FindValidator(addr)
+	if err != nil {
+		log.Errorf("error finding validator %s: %+v", addr, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding validator %s", addr))
+		return
+	}
+	if validator == nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("no payouts to validator %s", addr))
+		return
+	}
+	periods, err := d.FindValidatorPayoutPeriods(validator.ID, period)
+	if err != nil {
+		log.Errorf("error finding payout periods of validator %s: %+v", addr, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding payouts of validator %s", addr))
+		return
+	}
+	payouts, err := d.FindValidatorPayouts(validator.ID, limit)
+	if err != nil {
+		log.Errorf("error finding payouts of validator %s: %+v", addr, err)
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("error finding payouts of validator %s", addr))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, ValidatorPayouts{Validator: validator, Period: period, Periods: periods, Payouts: payouts})
+}
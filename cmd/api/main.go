@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"math/rand"
@@ -10,19 +11,26 @@ import (
 	"github.com/arkeonetwork/directory/api"
 	"github.com/arkeonetwork/directory/pkg/config"
 	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/tracing"
 )
 
 type Config struct {
-	ApiListenAddr  string `mapstructure:"API_LISTEN"`
-	ApiStaticDir   string `mapstructure:"API_STATIC_DIR"`
-	DBHost         string `mapstructure:"DB_HOST"`
-	DBPort         uint   `mapstructure:"DB_PORT"`
-	DBUser         string `mapstructure:"DB_USER"`
-	DBPass         string `mapstructure:"DB_PASS"`
-	DBName         string `mapstructure:"DB_NAME"`
-	DBSSLMode      string `mapstructure:"DB_SSL_MODE"`
-	DBPoolMaxConns int    `mapstructure:"DB_POOL_MAX_CONNS"`
-	DBPoolMinConns int    `mapstructure:"DB_POOL_MIN_CONNS"`
+	ApiListenAddr       string        `mapstructure:"API_LISTEN"`
+	ApiStaticDir        string        `mapstructure:"API_STATIC_DIR"`
+	MaxBlockAge         time.Duration `mapstructure:"HEALTH_MAX_BLOCK_AGE"`
+	TracingExporter     string        `mapstructure:"TRACING_EXPORTER"`
+	OTLPEndpoint        string        `mapstructure:"OTLP_ENDPOINT"`
+	OTLPInsecure        bool          `mapstructure:"OTLP_INSECURE"`
+	Bech32PrefixAccAddr string        `mapstructure:"BECH32_PREF_ACC_ADDR"`
+	Bech32PrefixAccPub  string        `mapstructure:"BECH32_PREF_ACC_PUB"`
+	DBHost              string        `mapstructure:"DB_HOST"`
+	DBPort              uint          `mapstructure:"DB_PORT"`
+	DBUser              string        `mapstructure:"DB_USER"`
+	DBPass              string        `mapstructure:"DB_PASS"`
+	DBName              string        `mapstructure:"DB_NAME"`
+	DBSSLMode           string        `mapstructure:"DB_SSL_MODE"`
+	DBPoolMaxConns      int           `mapstructure:"DB_POOL_MAX_CONNS"`
+	DBPoolMinConns      int           `mapstructure:"DB_POOL_MIN_CONNS"`
 }
 
 var (
@@ -31,6 +39,12 @@ var (
 	configNames = []string{
 		"API_LISTEN",
 		"API_STATIC_DIR",
+		"HEALTH_MAX_BLOCK_AGE",
+		"TRACING_EXPORTER",
+		"OTLP_ENDPOINT",
+		"OTLP_INSECURE",
+		"BECH32_PREF_ACC_ADDR",
+		"BECH32_PREF_ACC_PUB",
 		"DB_HOST",
 		"DB_PORT",
 		"DB_USER",
@@ -59,10 +73,24 @@ func main() {
 			log.Panicf("failed to load config: %+v", err)
 		}
 	}
+	shutdownTracing, err := tracing.Init(tracing.Config{
+		ServiceName:  "directory-api",
+		Exporter:     c.TracingExporter,
+		OTLPEndpoint: c.OTLPEndpoint,
+		OTLPInsecure: c.OTLPInsecure,
+	})
+	if err != nil {
+		log.Panicf("failed to initialize tracing: %+v", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	// TODO determine config mechanism
 	api := api.NewApiService(api.ApiServiceParams{
-		ListenAddr: c.ApiListenAddr,
-		StaticDir:  c.ApiStaticDir,
+		ListenAddr:          c.ApiListenAddr,
+		StaticDir:           c.ApiStaticDir,
+		MaxBlockAge:         c.MaxBlockAge,
+		Bech32PrefixAccAddr: c.Bech32PrefixAccAddr,
+		Bech32PrefixAccPub:  c.Bech32PrefixAccPub,
 		DBConfig: db.DBConfig{
 			Host:         c.DBHost,
 			Port:         c.DBPort,
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/arkeonetwork/directory/indexer"
+)
+
+func runGaps(c *indexer.Config, args []string) error {
+	d, err := openDB(c)
+	if err != nil {
+		return err
+	}
+	gaps, err := d.FindBlockGaps()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "START\tEND\tBLOCKS")
+	var missing int64
+	for _, g := range gaps {
+		fmt.Fprintf(w, "%d\t%d\t%d\n", g.Start, g.End, g.End-g.Start+1)
+		missing += g.End - g.Start + 1
+	}
+	w.Flush()
+	fmt.Printf("%d gaps, %d blocks missing\n", len(gaps), missing)
+	return nil
+}
+
+func runReindex(c *indexer.Config, args []string) error {
+	fs := newFlagSet("reindex -from <height> -to <height>")
+	from := fs.Int64("from", 0, "first height to reindex")
+	to := fs.Int64("to", 0, "last height to reindex, inclusive")
+	fs.Parse(args)
+	if *from <= 0 || *to < *from {
+		fs.Usage()
+		return fmt.Errorf("-from and -to must give a valid range")
+	}
+
+	app := indexer.NewIndexer(c.AppParams())
+	deletions, err := app.Reindex(*from, *to)
+	for _, del := range deletions {
+		fmt.Printf("%s: %d rows reset\n", del.Table, del.Rows)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("reindexed %d-%d, run gaps to check for blocks that failed to refill\n", *from, *to)
+	return nil
+}
+
+func runProvider(c *indexer.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: directory provider show|refresh-metadata -chain <chain> <pubkey>")
+	}
+	sub := args[0]
+	fs := newFlagSet("provider " + sub + " -chain <chain> <pubkey>")
+	chain := fs.String("chain", "", "provider chain")
+	fs.Parse(args[1:])
+	if *chain == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("a chain and pubkey are required")
+	}
+	pubkey := fs.Arg(0)
+
+	switch sub {
+	case "show":
+		d, err := openDB(c)
+		if err != nil {
+			return err
+		}
+		provider, err := d.FindProvider(pubkey, *chain)
+		if err != nil {
+			return err
+		}
+		if provider == nil {
+			return fmt.Errorf("no provider %s chain %s", pubkey, *chain)
+		}
+		status, err := d.FindMetadataStatus(pubkey, *chain)
+		if err != nil {
+			return err
+		}
+		contracts, err := d.FindProviderContracts(pubkey, *chain)
+		if err != nil {
+			return err
+		}
+		return printJSON(map[string]interface{}{
+			"Provider":       provider,
+			"MetadataStatus": status,
+			"ContractCount":  len(contracts),
+		})
+	case "refresh-metadata":
+		app := indexer.NewIndexer(c.AppParams())
+		status, err := app.RefreshProviderMetadata(pubkey, *chain)
+		if err != nil {
+			return err
+		}
+		return printJSON(status)
+	default:
+		return fmt.Errorf("unknown provider command %q", sub)
+	}
+}
+
+func runContract(c *indexer.Config, args []string) error {
+	if len(args) != 2 || args[0] != "show" {
+		return fmt.Errorf("usage: directory contract show <id>")
+	}
+	id, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid contract id %q", args[1])
+	}
+	d, err := openDB(c)
+	if err != nil {
+		return err
+	}
+	contract, err := d.FindContractByID(id)
+	if err != nil {
+		return err
+	}
+	if contract == nil {
+		return fmt.Errorf("no contract %d", id)
+	}
+	return printJSON(contract)
+}
+
+func runReconcile(c *indexer.Config, args []string) error {
+	fs := newFlagSet("reconcile [-repair]")
+	repair := fs.Bool("repair", false, "correct drift the chain's state is enough to repair")
+	fs.Parse(args)
+
+	app := indexer.NewIndexer(c.AppParams())
+	run, err := app.Reconcile(context.Background(), *repair)
+	if err != nil {
+		return err
+	}
+	d, err := openDB(c)
+	if err != nil {
+		return err
+	}
+	mismatches, err := d.FindReconcileMismatches(run.ID)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ENTITY	KEY	FIELD	DIRECTORY	CHAIN	REPAIRED")
+	for _, m := range mismatches {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\n", m.EntityType, m.EntityKey, m.Field, m.DirectoryValue, m.ChainValue, m.Repaired)
+	}
+	w.Flush()
+	fmt.Printf("checked %d providers and %d contracts at height %d: %d mismatches, %d repaired\n",
+		run.ProvidersChecked, run.ContractsChecked, run.ChainHeight, run.Mismatches, run.Repaired)
+	return nil
+}
+
+func runVerify(c *indexer.Config, args []string) error {
+	d, err := openDB(c)
+	if err != nil {
+		return err
+	}
+	checks, err := d.Verify()
+	if err != nil {
+		return err
+	}
+	gaps, err := d.FindBlockGaps()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tRESULT\tCOUNT\tDESCRIPTION")
+	var missing int64
+	for _, g := range gaps {
+		missing += g.End - g.Start + 1
+	}
+	if missing > 0 {
+		failed++
+	}
+	fmt.Fprintf(w, "block_gaps\t%s\t%d\tblocks missing below the latest indexed height\n", result(missing == 0), missing)
+	for _, check := range checks {
+		if !check.OK() {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", check.Check, result(check.OK()), check.Count, check.Description)
+	}
+	w.Flush()
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	return nil
+}
+
+func result(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "FAIL"
+}
+
+func runStats(c *indexer.Config, args []string) error {
+	d, err := openDB(c)
+	if err != nil {
+		return err
+	}
+	network, err := d.GetArkeoNetworkStats()
+	if err != nil {
+		return err
+	}
+	latest, err := d.FindLatestBlock()
+	if err != nil {
+		return err
+	}
+	status, err := d.FindLatestIndexerStatus()
+	if err != nil {
+		return err
+	}
+	counts, err := d.FindEventCounts()
+	if err != nil {
+		return err
+	}
+	events := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		events[count.EventType] = count.Count
+	}
+	return printJSON(map[string]interface{}{
+		"Network":       network,
+		"LatestBlock":   latest,
+		"IndexerStatus": status,
+		"EventCounts":   events,
+	})
+}
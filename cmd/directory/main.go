@@ -0,0 +1,93 @@
+// Command directory is an admin tool for inspecting and repairing the directory database. It reads the same env
+// configuration as cmd/indexer.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/arkeonetwork/directory/indexer"
+	"github.com/arkeonetwork/directory/pkg/db"
+)
+
+var envPath = flag.String("env", "", "path to env file (default: use os env)")
+
+type command struct {
+	name  string
+	usage string
+	run   func(c *indexer.Config, args []string) error
+}
+
+var commands = []command{
+	{"gaps", "gaps", runGaps},
+	{"reindex", "reindex -from <height> -to <height>", runReindex},
+	{"provider", "provider show|refresh-metadata -chain <chain> <pubkey>", runProvider},
+	{"contract", "contract show <id>", runContract},
+	{"verify", "verify", runVerify},
+	{"reconcile", "reconcile [-repair]", runReconcile},
+	{"stats", "stats", runStats},
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "usage: directory [-env <file>] <command> [args]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s\n", cmd.usage)
+	}
+	fmt.Fprintln(flag.CommandLine.Output())
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	name, args := flag.Arg(0), flag.Args()[1:]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		c, err := indexer.LoadConfig(*envPath)
+		if err != nil {
+			fail(err)
+		}
+		if err = cmd.run(c, args); err != nil {
+			fail(err)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
+
+func openDB(c *indexer.Config) (*db.DirectoryDB, error) {
+	return db.New(c.DBConfig())
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// subcommand flags are parsed ahead of positional args, as with the flag package generally
+func newFlagSet(usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(strings.Fields(usage)[0], flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: directory %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
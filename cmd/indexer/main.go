@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"math/rand"
@@ -8,47 +9,18 @@ import (
 
 	"github.com/arkeonetwork/common/logging"
 	"github.com/arkeonetwork/directory/indexer"
-	"github.com/arkeonetwork/directory/pkg/config"
-	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/tracing"
 )
 
-type Config struct {
-	ArkeoApi            string `mapstructure:"ARKEO_API"`
-	TendermintApi       string `mapstructure:"TENDERMINT_API"`
-	TendermintWs        string `mapstructure:"TENDERMINT_WS"`
-	ChainID             string `mapstructure:"CHAIN_ID"`
-	Bech32PrefixAccAddr string `mapstructure:"BECH32_PREF_ACC_ADDR"`
-	Bech32PrefixAccPub  string `mapstructure:"BECH32_PREF_ACC_PUB"`
-	DBHost              string `mapstructure:"DB_HOST"`
-	DBPort              uint   `mapstructure:"DB_PORT"`
-	DBUser              string `mapstructure:"DB_USER"`
-	DBPass              string `mapstructure:"DB_PASS"`
-	DBName              string `mapstructure:"DB_NAME"`
-	DBSSLMode           string `mapstructure:"DB_SSL_MODE"`
-	DBPoolMaxConns      int    `mapstructure:"DB_POOL_MAX_CONNS"`
-	DBPoolMinConns      int    `mapstructure:"DB_POOL_MIN_CONNS"`
-}
+var (
+	log     = logging.WithoutFields()
+	envPath = flag.String("env", "", "path to env file (default: use os env)")
+)
 
+// dev only, metadata uris are provider controlled
 var (
-	log         = logging.WithoutFields()
-	envPath     = flag.String("env", "", "path to env file (default: use os env)")
-	configNames = []string{
-		"ARKEO_API",
-		"TENDERMINT_API",
-		"TENDERMINT_WS",
-		"CHAIN_ID",
-		"INDEXER_ID",
-		"BECH32_PREF_ACC_ADDR",
-		"BECH32_PREF_ACC_PUB",
-		"DB_HOST",
-		"DB_PORT",
-		"DB_USER",
-		"DB_PASS",
-		"DB_NAME",
-		"DB_SSL_MODE",
-		"DB_POOL_MAX_CONNS",
-		"DB_POOL_MIN_CONNS",
-	}
+	allowFileMetadata    = flag.Bool("dev-allow-file-metadata", false, "allow file:// provider metadata uris (development only)")
+	allowPrivateMetadata = flag.Bool("dev-allow-private-metadata", false, "allow provider metadata on loopback/private addresses (development only)")
 )
 
 func init() {
@@ -58,35 +30,21 @@ func init() {
 func main() {
 	log.Info("starting indexer")
 	flag.Parse()
-	c := &Config{}
-	if *envPath == "" {
-		if err := config.LoadFromEnv(c, configNames...); err != nil {
-			log.Panicf("failed to load config from env: %+v", err)
-		}
-	} else {
-		if err := config.Load(*envPath, c); err != nil {
-			log.Panicf("failed to load config: %+v", err)
-		}
+	c, err := indexer.LoadConfig(*envPath)
+	if err != nil {
+		log.Panicf("%+v", err)
+	}
+
+	shutdownTracing, err := tracing.Init(c.TracingConfig("directory-indexer"))
+	if err != nil {
+		log.Panicf("failed to initialize tracing: %+v", err)
 	}
+	defer shutdownTracing(context.Background())
 
-	app := indexer.NewIndexer(indexer.IndexerAppParams{
-		ChainID:             c.ChainID,
-		Bech32PrefixAccAddr: c.Bech32PrefixAccAddr,
-		Bech32PrefixAccPub:  c.Bech32PrefixAccPub,
-		ArkeoApi:            c.ArkeoApi,
-		TendermintApi:       c.TendermintApi,
-		TendermintWs:        c.TendermintWs,
-		DBConfig: db.DBConfig{
-			Host:         c.DBHost,
-			Port:         c.DBPort,
-			User:         c.DBUser,
-			Pass:         c.DBPass,
-			DBName:       c.DBName,
-			PoolMaxConns: c.DBPoolMaxConns,
-			PoolMinConns: c.DBPoolMinConns,
-			SSLMode:      c.DBSSLMode,
-		},
-	})
+	params := c.AppParams()
+	params.AllowFileMetadata = *allowFileMetadata
+	params.AllowPrivateMetadata = *allowPrivateMetadata
+	app := indexer.NewIndexer(params)
 	done, err := app.Run()
 	if err != nil {
 		panic(fmt.Sprintf("error starting indexer: %+v", err))
@@ -0,0 +1,32 @@
+create table provider_metadata_status
+(
+    id             bigserial                 not null
+        constraint provider_metadata_status_pk
+            primary key,
+    created        timestamptz default now() not null,
+    updated        timestamptz default now() not null,
+    provider_id    bigint                    not null references providers (id),
+    metadata_uri   text                      not null check ( metadata_uri != '' ),
+    metadata_nonce numeric                   not null check ( metadata_nonce >= 0 ),
+    status         text                      not null,
+    attempts       integer     default 0     not null,
+    last_error     text,
+    last_attempt   timestamptz,
+    last_success   timestamptz,
+    next_attempt   timestamptz default now() not null
+);
+
+alter table provider_metadata_status
+    add constraint prov_meta_status_prov_uniq unique (provider_id);
+
+create index prov_meta_status_next_attempt_idx on provider_metadata_status (next_attempt);
+
+-- queue a fetch for every provider that already has a metadata uri
+insert into provider_metadata_status(provider_id, metadata_uri, metadata_nonce, status)
+select p.id, p.metadata_uri, coalesce(p.metadata_nonce, 0), 'Pending'
+from providers p
+where p.metadata_uri is not null
+  and p.metadata_uri != '';
+
+---- create above / drop below ----
+drop table provider_metadata_status;
@@ -0,0 +1,10 @@
+alter table provider_metadata add column schema_version text;
+alter table provider_metadata add column validation_warnings text[] not null default '{}';
+alter table provider_metadata add column validation_errors text[] not null default '{}';
+alter table provider_metadata add column is_valid boolean not null default true;
+
+---- create above / drop below ----
+alter table provider_metadata drop column is_valid;
+alter table provider_metadata drop column validation_errors;
+alter table provider_metadata drop column validation_warnings;
+alter table provider_metadata drop column schema_version;
@@ -0,0 +1,12 @@
+alter table provider_metadata add column raw_document bytea;
+alter table provider_metadata add column content_hash text;
+alter table provider_metadata add column content_drift boolean not null default false;
+alter table provider_metadata add column drift_hash text;
+alter table provider_metadata add column drift_detected timestamptz;
+
+---- create above / drop below ----
+alter table provider_metadata drop column drift_detected;
+alter table provider_metadata drop column drift_hash;
+alter table provider_metadata drop column content_drift;
+alter table provider_metadata drop column content_hash;
+alter table provider_metadata drop column raw_document;
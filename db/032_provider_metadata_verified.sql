@@ -0,0 +1,4 @@
+alter table provider_metadata add column verified boolean not null default false;
+
+---- create above / drop below ----
+alter table provider_metadata drop column verified;
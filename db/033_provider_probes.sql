@@ -0,0 +1,24 @@
+create table provider_probes
+(
+    id          bigserial                 not null
+        constraint provider_probes_pk
+            primary key,
+    created     timestamptz default now() not null,
+    updated     timestamptz default now() not null,
+    provider_id bigint                    not null references providers (id),
+    endpoint    text                      not null,
+    success     boolean                   not null,
+    status_code integer,
+    latency_ms  bigint                    not null check ( latency_ms >= 0 ),
+    error       text,
+    probed_at   timestamptz default now() not null
+);
+
+create index provider_probes_provider_probed_idx on provider_probes (provider_id, probed_at desc);
+create index provider_probes_probed_idx on provider_probes (probed_at);
+
+{{ template "views/provider_probe_stats_v.sql" . }}
+
+---- create above / drop below ----
+drop view provider_probe_stats_v;
+drop table provider_probes;
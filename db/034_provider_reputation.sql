@@ -0,0 +1,26 @@
+create table provider_reputation
+(
+    id                     bigserial                 not null
+        constraint provider_reputation_pk
+            primary key,
+    created                timestamptz default now() not null,
+    updated                timestamptz default now() not null,
+    provider_id            bigint                    not null references providers (id),
+    score                  numeric                   not null check ( score >= 0 and score <= 100 ),
+    age_score              numeric                   not null,
+    bond_score             numeric                   not null,
+    contracts_score        numeric                   not null,
+    settlement_score       numeric                   not null,
+    validator_score        numeric                   not null,
+    metadata_score         numeric, -- null when the provider has no fetched metadata
+    completed_contracts    bigint                    not null,
+    early_closed_contracts bigint                    not null,
+    computed_height        bigint                    not null
+);
+
+alter table provider_reputation
+    add constraint provider_reputation_prov_uniq unique (provider_id);
+create index provider_reputation_score_idx on provider_reputation (score desc);
+
+---- create above / drop below ----
+drop table provider_reputation;
@@ -0,0 +1,13 @@
+create table avg_block_time
+(
+    id             numeric                   not null
+        constraint avg_block_time_pk
+            primary key,
+    created        timestamptz default now() not null,
+    updated        timestamptz default now() not null,
+    avg_block_time numeric                   not null check ( avg_block_time > 0 ), -- seconds per block
+    window_blocks  bigint                    not null
+);
+
+---- create above / drop below ----
+drop table avg_block_time;
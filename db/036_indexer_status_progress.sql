@@ -0,0 +1,14 @@
+alter table indexer_status add column chain_tip numeric;
+alter table indexer_status add column chain_tip_updated timestamptz;
+alter table indexer_status add column gap_fill_blocks bigint not null default 0; -- blocks to fill in the current or last run
+alter table indexer_status add column gap_fill_done bigint not null default 0;
+alter table indexer_status add column gap_fill_started timestamptz;
+alter table indexer_status add column gap_fill_finished timestamptz;
+
+---- create above / drop below ----
+alter table indexer_status drop column gap_fill_finished;
+alter table indexer_status drop column gap_fill_started;
+alter table indexer_status drop column gap_fill_done;
+alter table indexer_status drop column gap_fill_blocks;
+alter table indexer_status drop column chain_tip_updated;
+alter table indexer_status drop column chain_tip;
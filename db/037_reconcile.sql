@@ -0,0 +1,38 @@
+create table reconcile_runs
+(
+    id                  bigserial                 not null
+        constraint reconcile_runs_pk
+            primary key,
+    created             timestamptz default now() not null,
+    updated             timestamptz default now() not null,
+    chain_height        bigint                    not null default 0, -- chain height the arkeo api reported
+    repair              boolean                   not null default false,
+    providers_checked   bigint                    not null default 0,
+    contracts_checked   bigint                    not null default 0,
+    mismatches          bigint                    not null default 0,
+    repaired            bigint                    not null default 0,
+    finished            timestamptz,
+    error               text
+);
+
+create table reconcile_mismatches
+(
+    id              bigserial                 not null
+        constraint reconcile_mismatches_pk
+            primary key,
+    created         timestamptz default now() not null,
+    updated         timestamptz default now() not null,
+    run_id          bigint                    not null references reconcile_runs (id) on delete cascade,
+    entity_type     text                      not null, -- provider or contract
+    entity_key      text                      not null,
+    field           text                      not null,
+    directory_value text                      not null,
+    chain_value     text                      not null,
+    repaired        boolean                   not null default false
+);
+
+create index reconcile_mismatches_run_idx on reconcile_mismatches (run_id);
+
+---- create above / drop below ----
+drop table reconcile_mismatches;
+drop table reconcile_runs;
@@ -0,0 +1,26 @@
+create table chains
+(
+    id                bigserial                 not null
+        constraint chains_pk
+            primary key,
+    created           timestamptz default now() not null,
+    updated           timestamptz default now() not null,
+    chain             text                      not null
+        constraint chains_chain_key
+            unique,
+    name              text                      not null,
+    family            text                      not null default '',
+    network_type      text                      not null default '',
+    source            text                      not null check ( source in ('config', 'observed') ),
+    first_seen_height bigint -- height of the first bond against the chain, null for chains only configured
+);
+
+-- chains already bonded against, the indexer seeds display metadata from its registry on startup
+insert into chains(chain, name, source, first_seen_height)
+select p.chain, p.chain, 'observed', min(e.height)
+from providers p
+         left join provider_bond_events e on e.provider_id = p.id
+group by p.chain;
+
+---- create above / drop below ----
+drop table chains;
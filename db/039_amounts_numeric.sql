@@ -0,0 +1,33 @@
+-- amounts are arbitrary precision, views over the amount columns are dropped to change their type
+drop view if exists network_stats_v;
+{{ template "views/drop.sql" . }}
+
+alter table contracts
+    alter column rate type numeric,
+    alter column open_cost type numeric;
+alter table open_contract_events
+    alter column rate type numeric,
+    alter column open_cost type numeric;
+alter table contract_settlement_events
+    alter column paid type numeric,
+    alter column reserve type numeric;
+
+{{ template "views/create.sql" . }}
+{{ template "views/network_stats_v.sql" . }}
+
+---- create above / drop below ----
+drop view if exists network_stats_v;
+{{ template "views/drop.sql" . }}
+
+alter table contracts
+    alter column rate type bigint,
+    alter column open_cost type bigint;
+alter table open_contract_events
+    alter column rate type bigint,
+    alter column open_cost type bigint;
+alter table contract_settlement_events
+    alter column paid type bigint,
+    alter column reserve type bigint;
+
+{{ template "views/create.sql" . }}
+{{ template "views/network_stats_v.sql" . }}
@@ -0,0 +1,18 @@
+-- account addresses derived from provider and client pubkeys, filled by the indexer as it sees each pubkey
+create table pubkey_addresses
+(
+    id      bigserial                 not null
+        constraint pubkey_addresses_pk
+            primary key,
+    created timestamptz default now() not null,
+    updated timestamptz default now() not null,
+    pubkey  text                      not null
+        constraint pubkey_addresses_pubkey_key
+            unique,
+    address text                      not null
+);
+
+create index pubkey_addresses_address_idx on pubkey_addresses (address);
+
+---- create above / drop below ----
+drop table pubkey_addresses;
@@ -0,0 +1,41 @@
+-- validators that have received payouts, keyed by operator address. the account address sharing the operator's key
+-- links a validator to a provider run by the same key and is filled by the indexer
+create table validators
+(
+    id                  bigserial                 not null
+        constraint validators_pk
+            primary key,
+    created             timestamptz default now() not null,
+    updated             timestamptz default now() not null,
+    operator            text                      not null
+        constraint validators_operator_key
+            unique,
+    address             text,
+    first_payout_height bigint                    not null
+);
+
+create index validators_address_idx on validators (address);
+
+insert into validators(operator, first_payout_height)
+select validator, min(height)
+from validator_payout_events
+group by validator;
+
+alter table validator_payout_events
+    add column validator_id bigint references validators (id),
+    add column txid         text;
+
+update validator_payout_events e
+set validator_id = v.id
+from validators v
+where v.operator = e.validator;
+
+alter table validator_payout_events alter column validator_id set not null;
+
+create index validator_payout_evts_validator_id_idx on validator_payout_events (validator_id, height);
+
+---- create above / drop below ----
+alter table validator_payout_events
+    drop column validator_id,
+    drop column txid;
+drop table validators;
@@ -0,0 +1,8 @@
+-- the chain's identifier of a contract, taken from its events. contracts indexed from events without one keep a null
+-- id and are resolved by provider, delegate and height
+alter table contracts add column contract_id bigint;
+alter table contracts add constraint contracts_contract_id_key unique (contract_id);
+
+---- create above / drop below ----
+alter table contracts drop constraint contracts_contract_id_key;
+alter table contracts drop column contract_id;
@@ -0,0 +1,55 @@
+-- a tx can carry several messages each emitting several events, events are keyed by their position in the tx rather
+-- than the tx alone. existing rows were unique on txid and take the first position
+alter table provider_bond_events
+    add column msg_index   int not null default 0,
+    add column event_index int not null default 0;
+alter table provider_bond_events drop constraint provider_bond_events_txid_unq;
+alter table provider_bond_events
+    add constraint provider_bond_events_txid_msg_evt_key unique (txid, msg_index, event_index);
+
+alter table provider_mod_events
+    add column msg_index   int not null default 0,
+    add column event_index int not null default 0;
+alter table provider_mod_events drop constraint provider_mod_events_txid_unq;
+alter table provider_mod_events
+    add constraint provider_mod_events_txid_msg_evt_key unique (txid, msg_index, event_index);
+
+alter table open_contract_events
+    add column msg_index   int not null default 0,
+    add column event_index int not null default 0;
+alter table open_contract_events drop constraint open_contract_events_txid_unq;
+alter table open_contract_events
+    add constraint open_contract_events_txid_msg_evt_key unique (txid, msg_index, event_index);
+
+alter table close_contract_events
+    add column msg_index   int not null default 0,
+    add column event_index int not null default 0;
+alter table close_contract_events drop constraint close_contract_events_txid_key;
+alter table close_contract_events
+    add constraint close_contract_events_txid_msg_evt_key unique (txid, msg_index, event_index);
+
+---- create above / drop below ----
+-- only the first event of each tx fits the txid keys
+delete from close_contract_events e
+where exists (select 1 from close_contract_events f where f.txid = e.txid and (f.msg_index, f.event_index) < (e.msg_index, e.event_index));
+alter table close_contract_events drop constraint close_contract_events_txid_msg_evt_key;
+alter table close_contract_events add constraint close_contract_events_txid_key unique (txid);
+alter table close_contract_events drop column msg_index, drop column event_index;
+
+delete from open_contract_events e
+where exists (select 1 from open_contract_events f where f.txid = e.txid and (f.msg_index, f.event_index) < (e.msg_index, e.event_index));
+alter table open_contract_events drop constraint open_contract_events_txid_msg_evt_key;
+alter table open_contract_events add constraint open_contract_events_txid_unq unique (txid);
+alter table open_contract_events drop column msg_index, drop column event_index;
+
+delete from provider_mod_events e
+where exists (select 1 from provider_mod_events f where f.txid = e.txid and (f.msg_index, f.event_index) < (e.msg_index, e.event_index));
+alter table provider_mod_events drop constraint provider_mod_events_txid_msg_evt_key;
+alter table provider_mod_events add constraint provider_mod_events_txid_unq unique (txid);
+alter table provider_mod_events drop column msg_index, drop column event_index;
+
+delete from provider_bond_events e
+where exists (select 1 from provider_bond_events f where f.txid = e.txid and (f.msg_index, f.event_index) < (e.msg_index, e.event_index));
+alter table provider_bond_events drop constraint provider_bond_events_txid_msg_evt_key;
+alter table provider_bond_events add constraint provider_bond_events_txid_unq unique (txid);
+alter table provider_bond_events drop column msg_index, drop column event_index;
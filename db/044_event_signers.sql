@@ -0,0 +1,63 @@
+-- the account that signed the message emitting each event, the authz grantee when executed through MsgExec, and the
+-- fee payer and memo of the tx. null for events indexed before signers were recorded
+alter table provider_bond_events
+    add column signer       text,
+    add column exec_grantee text,
+    add column fee_payer    text,
+    add column memo         text;
+create index provider_bond_evts_signer_idx on provider_bond_events (signer);
+
+alter table provider_mod_events
+    add column signer       text,
+    add column exec_grantee text,
+    add column fee_payer    text,
+    add column memo         text;
+create index provider_mod_evts_signer_idx on provider_mod_events (signer);
+
+alter table open_contract_events
+    add column signer       text,
+    add column exec_grantee text,
+    add column fee_payer    text,
+    add column memo         text;
+create index open_contract_evts_signer_idx on open_contract_events (signer);
+
+alter table close_contract_events
+    add column signer       text,
+    add column exec_grantee text,
+    add column fee_payer    text,
+    add column memo         text;
+create index close_contract_evts_signer_idx on close_contract_events (signer);
+
+alter table contract_settlement_events
+    add column signer       text,
+    add column exec_grantee text,
+    add column fee_payer    text,
+    add column memo         text;
+create index contract_settlement_evts_signer_idx on contract_settlement_events (signer);
+
+---- create above / drop below ----
+alter table contract_settlement_events
+    drop column signer,
+    drop column exec_grantee,
+    drop column fee_payer,
+    drop column memo;
+alter table close_contract_events
+    drop column signer,
+    drop column exec_grantee,
+    drop column fee_payer,
+    drop column memo;
+alter table open_contract_events
+    drop column signer,
+    drop column exec_grantee,
+    drop column fee_payer,
+    drop column memo;
+alter table provider_mod_events
+    drop column signer,
+    drop column exec_grantee,
+    drop column fee_payer,
+    drop column memo;
+alter table provider_bond_events
+    drop column signer,
+    drop column exec_grantee,
+    drop column fee_payer,
+    drop column memo;
@@ -0,0 +1,29 @@
+-- txs which carried an arkeo event. fee is the part of the fee paid in the default denom, fee_coins all of it
+create table txs
+(
+    id         bigserial                 not null
+        constraint txs_pk
+            primary key,
+    created    timestamptz default now() not null,
+    updated    timestamptz default now() not null,
+    txid       text                      not null check ( txid != '' )
+        constraint txs_txid_key
+            unique,
+    height     bigint                    not null check ( height > 0 ),
+    signer     text,
+    fee_payer  text,
+    memo       text,
+    fee        numeric                   not null default 0,
+    fee_coins  text                      not null default '',
+    gas_wanted bigint                    not null,
+    gas_used   bigint                    not null,
+    code       int                       not null,
+    success    boolean                   not null
+);
+
+create index txs_height_idx on txs (height);
+create index txs_fee_payer_idx on txs (fee_payer);
+create index txs_signer_idx on txs (signer);
+
+---- create above / drop below ----
+drop table txs;
@@ -0,0 +1,32 @@
+-- arkeo messages of txs which failed, one row per message. msg holds the message's decoded protobuf fields by field
+-- number as arkeo's types aren't known to the indexer, provider is the provider pubkey the message names if any.
+-- failed txs change no state and are kept out of every projection
+create table failed_txs
+(
+    id           bigserial                 not null
+        constraint failed_txs_pk
+            primary key,
+    created      timestamptz default now() not null,
+    updated      timestamptz default now() not null,
+    txid         text                      not null check ( txid != '' ),
+    msg_index    int                       not null,
+    height       bigint                    not null check ( height > 0 ),
+    msg_type     text                      not null,
+    msg          jsonb                     not null,
+    provider     text,
+    signer       text,
+    exec_grantee text,
+    fee_payer    text,
+    memo         text,
+    code         int                       not null,
+    codespace    text                      not null default '',
+    log          text                      not null default ''
+);
+
+alter table failed_txs add constraint failed_txs_txid_msg_key unique (txid, msg_index);
+create index failed_txs_signer_idx on failed_txs (signer);
+create index failed_txs_provider_idx on failed_txs (provider);
+create index failed_txs_height_idx on failed_txs (height);
+
+---- create above / drop below ----
+drop table failed_txs;
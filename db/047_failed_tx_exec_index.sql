@@ -0,0 +1,10 @@
+-- msg_index is the tx's top-level message index as on the event tables, exec_index the message's position among
+-- those a MsgExec wraps, 0 for a message sent directly
+alter table failed_txs add column exec_index int not null default 0;
+alter table failed_txs drop constraint failed_txs_txid_msg_key;
+alter table failed_txs add constraint failed_txs_txid_msg_key unique (txid, msg_index, exec_index);
+
+---- create above / drop below ----
+alter table failed_txs drop constraint failed_txs_txid_msg_key;
+alter table failed_txs add constraint failed_txs_txid_msg_key unique (txid, msg_index);
+alter table failed_txs drop column exec_index;
@@ -0,0 +1,22 @@
+-- availability and latency of each provider's sentinel as observed by the prober over the last 24 hours.
+-- latency percentiles only consider successful probes
+create or replace view provider_probe_stats_v as
+(
+select pr.provider_id,
+       count(1)                                                                          as probe_count,
+       avg(case when pr.success then 1.0 else 0.0 end)                                   as uptime,
+       percentile_cont(0.5) within group (order by pr.latency_ms) filter (where pr.success)  as latency_p50,
+       percentile_cont(0.9) within group (order by pr.latency_ms) filter (where pr.success)  as latency_p90,
+       percentile_cont(0.99) within group (order by pr.latency_ms) filter (where pr.success) as latency_p99,
+       max(pr.probed_at)                                                                 as last_probed,
+       max(pr.probed_at) filter (where pr.success)                                       as last_success,
+       (select e.error
+        from provider_probes e
+        where e.provider_id = pr.provider_id
+          and not e.success
+        order by e.probed_at desc
+        limit 1)                                                                         as last_error
+from provider_probes pr
+where pr.probed_at > now() - interval '24 hours'
+group by pr.provider_id
+    );
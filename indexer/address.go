@@ -0,0 +1,64 @@
+package indexer
+
+import (
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// recordAddress stores the account address derived from a provider or client pubkey so either form can be used to
+// look the account up
+func (a *IndexerApp) recordAddress(d *db.DirectoryDB, pubkey string) error {
+	if pubkey == "" {
+		return nil
+	}
+	addr, err := utils.PubkeyToAddress(pubkey, a.params.Bech32PrefixAccPub, a.params.Bech32PrefixAccAddr)
+	if err != nil {
+		return errors.Wrapf(err, "error deriving address of %s", pubkey)
+	}
+	if _, err = d.UpsertPubkeyAddress(pubkey, addr); err != nil {
+		return errors.Wrapf(err, "error storing address of %s", pubkey)
+	}
+	return nil
+}
+
+// backfillAddresses derives addresses for pubkeys and validators indexed before addresses were stored. keys which
+// fail to decode are logged and skipped rather than blocking startup
+func (a *IndexerApp) backfillAddresses() error {
+	pubkeys, err := a.db.FindPubkeysWithoutAddress()
+	if err != nil {
+		return errors.Wrapf(err, "error finding pubkeys without address")
+	}
+	stored := 0
+	for _, pubkey := range pubkeys {
+		if err = a.recordAddress(a.db, pubkey); err != nil {
+			log.Warnf("skipping address backfill: %+v", err)
+			continue
+		}
+		stored++
+	}
+	if len(pubkeys) > 0 {
+		log.Infof("backfilled %d of %d pubkey addresses", stored, len(pubkeys))
+	}
+
+	validators, err := a.db.FindValidatorsWithoutAddress()
+	if err != nil {
+		return errors.Wrapf(err, "error finding validators without address")
+	}
+	stored = 0
+	for _, v := range validators {
+		addr, err := utils.ValoperToAccAddress(v.Operator, a.params.Bech32PrefixAccAddr)
+		if err != nil {
+			log.Warnf("skipping validator address backfill for %s: %+v", v.Operator, err)
+			continue
+		}
+		if _, err = a.db.UpsertValidator(v.Operator, addr, v.FirstPayoutHeight); err != nil {
+			return errors.Wrapf(err, "error storing address of validator %s", v.Operator)
+		}
+		stored++
+	}
+	if len(validators) > 0 {
+		log.Infof("backfilled %d of %d validator addresses", stored, len(validators))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// Reindex deletes what was indexed from heights from through to inclusive, replays the range from the chain and
+// rebuilds provider and contract state from the latest events. the range is decoded with the decoder versions
+// scheduled as when running
+func (a *IndexerApp) Reindex(from, to int64) ([]*db.RangeDeletion, error) {
+	if from <= 0 || to < from {
+		return nil, fmt.Errorf("invalid range %d-%d", from, to)
+	}
+	if err := a.loadDecoders(context.Background()); err != nil {
+		return nil, errors.Wrapf(err, "error loading event decoders")
+	}
+	deletions, err := a.db.DeleteIndexedRange(from, to)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error deleting range %d-%d", from, to)
+	}
+	// blocks that fail are left as gaps for the gap filler
+	if err = a.fillGap(db.BlockGap{Start: from, End: to}); err != nil {
+		return deletions, errors.Wrapf(err, "error refilling range %d-%d", from, to)
+	}
+	if err = a.db.RebuildProjections(); err != nil {
+		return deletions, errors.Wrapf(err, "error rebuilding projections")
+	}
+	return deletions, nil
+}
+
+// RefreshProviderMetadata fetches a provider's metadata now rather than waiting for the metadata worker, queueing
+// the provider's current uri first if it was never queued
+func (a *IndexerApp) RefreshProviderMetadata(pubkey, chain string) (*db.ProviderMetadataStatus, error) {
+	status, err := a.db.FindMetadataStatus(pubkey, chain)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error finding metadata status")
+	}
+	if status == nil {
+		provider, err := a.db.FindProvider(pubkey, chain)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error finding provider %s chain %s", pubkey, chain)
+		}
+		if provider == nil {
+			return nil, fmt.Errorf("no provider %s chain %s", pubkey, chain)
+		}
+		if err = a.metadataFetcher.ValidateURI(provider.MetadataURI); err != nil {
+			return nil, errors.Wrapf(err, "invalid metadata uri %q", provider.MetadataURI)
+		}
+		if _, err = a.db.QueueMetadataFetch(provider.ID, provider.MetadataURI, provider.MetadataNonce); err != nil {
+			return nil, errors.Wrapf(err, "error queueing metadata fetch")
+		}
+		if status, err = a.db.FindMetadataStatus(pubkey, chain); err != nil || status == nil {
+			return nil, fmt.Errorf("error finding queued metadata status: %v", err)
+		}
+	}
+
+	recorded, err := a.refreshMetadata(status)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error refreshing metadata from %s", status.MetadataURI)
+	}
+	if !recorded {
+		return nil, fmt.Errorf("error recording metadata fetch from %s", status.MetadataURI)
+	}
+	return a.db.FindMetadataStatus(pubkey, chain)
+}
@@ -0,0 +1,22 @@
+package indexer
+
+import "time"
+
+const (
+	blockTimeInterval = time.Minute
+	// number of most recent blocks averaged over
+	blockTimeWindow = 1000
+)
+
+// blockTimeWorker keeps the rolling average block time used to estimate wall-clock times from heights
+func (a *IndexerApp) blockTimeWorker() {
+	log.Infof("starting block time worker")
+	ticker := time.NewTicker(blockTimeInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := a.db.UpdateAverageBlockTime(a.params.IndexerID, blockTimeWindow); err != nil {
+			log.Errorf("error updating average block time: %+v", err)
+		}
+		<-ticker.C
+	}
+}
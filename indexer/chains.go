@@ -0,0 +1,22 @@
+package indexer
+
+import (
+	"github.com/arkeonetwork/directory/pkg/chains"
+	"github.com/pkg/errors"
+)
+
+// seedChains registers the chains of the configured registry, chains seen only on-chain are added as bond events
+// are indexed
+func (a *IndexerApp) seedChains() error {
+	registry, err := chains.Load(a.params.ChainRegistryFile)
+	if err != nil {
+		return err
+	}
+	for _, c := range registry {
+		if _, err = a.db.UpsertConfiguredChain(c); err != nil {
+			return errors.Wrapf(err, "error registering chain %s", c.Chain)
+		}
+	}
+	log.Infof("seeded %d chains", len(registry))
+	return nil
+}
@@ -13,9 +13,14 @@ import (
 	"time"
 
 	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/metrics"
+	"github.com/arkeonetwork/directory/pkg/tracing"
 	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/arkeonetwork/directory/pkg/utils"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 
 	abcitypes "github.com/tendermint/tendermint/abci/types"
 	tmclient "github.com/tendermint/tendermint/rpc/client/http"
@@ -29,32 +34,23 @@ import (
 
 type attributes func() map[string]string
 
-func wsAttributeSource(src ctypes.ResultEvent) func() map[string]string {
-	attribs := make(map[string]string, len(src.Events))
-	for k, v := range src.Events {
-		if len(v) > 0 {
-			key := k
-			if sl := strings.Split(k, "."); len(sl) > 1 {
-				key = sl[1]
-			}
-			if _, ok := attribs[key]; ok {
-				log.Debugf("key %s already in results with value %s, overwriting with %s", key, attribs[key], v[0])
-			}
-			attribs[key] = v[0]
-		}
-		if len(v) > 1 {
-			log.Warnf("attrib %s has %d array values: %v", k, len(v), v)
-		}
-	}
-	attribs["eventHeight"] = attribs["height"]
-	return func() map[string]string { return attribs }
+// where a tx event came from, its position in the tx and the message's signer
+type txEventSource struct {
+	index  types.TxEventIndex
+	signer types.TxSigner
 }
 
-func tmAttributeSource(tx tmtypes.Tx, evt abcitypes.Event, height int64) func() map[string]string {
+func tmAttributeSource(tx tmtypes.Tx, evt abcitypes.Event, height int64, src txEventSource) func() map[string]string {
 	attribs := make(map[string]string, 0)
 	for _, attr := range evt.Attributes {
 		attribs[string(attr.Key)] = string(attr.Value)
 	}
+	attribs["msgIndex"] = strconv.Itoa(src.index.MsgIndex)
+	attribs["eventIndex"] = strconv.Itoa(src.index.EventIndex)
+	attribs["msgSigner"] = src.signer.Signer
+	attribs["msgGrantee"] = src.signer.Grantee
+	attribs["txFeePayer"] = src.signer.FeePayer
+	attribs["txMemo"] = src.signer.Memo
 
 	if tx != nil {
 		if _, ok := attribs["hash"]; !ok {
@@ -70,21 +66,38 @@ func tmAttributeSource(tx tmtypes.Tx, evt abcitypes.Event, height int64) func()
 	return func() map[string]string { return attribs }
 }
 
-func (a *IndexerApp) handleValidatorPayoutEvent(evt types.ValidatorPayoutEvent) error {
+func (a *IndexerApp) handleValidatorPayoutEvent(ctx context.Context, evt types.ValidatorPayoutEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "handleValidatorPayoutEvent")
+	defer func() { tracing.End(span, err) }()
+	d := a.db.WithContext(ctx)
+
 	log.Infof("receieved validatorPayoutEvent %#v", evt)
-	if evt.Paid < 0 {
-		return fmt.Errorf("received negative paid amt: %d for tx %s", evt.Paid, evt.TxID)
+	if evt.Paid.Sign() < 0 {
+		return fmt.Errorf("received negative paid amt: %s for tx %s", evt.Paid, evt.TxID)
 	}
-	if evt.Paid == 0 {
+	if evt.Paid.IsZero() {
 		return nil
 	}
+	// the account address links the validator to a provider run by the same key, it is derived again at startup if
+	// this fails
+	addr, err := utils.ValoperToAccAddress(evt.Validator, a.params.Bech32PrefixAccAddr)
+	if err != nil {
+		log.Warnf("validator %s indexed without address: %+v", evt.Validator, err)
+	}
+	validator, err := d.UpsertValidator(evt.Validator, addr, evt.Height)
+	if err != nil {
+		return errors.Wrapf(err, "error upserting validator %s", evt.Validator)
+	}
 	log.Infof("upserting validator payout event for tx %s", evt.TxID)
-	if _, err := a.db.UpsertValidatorPayoutEvent(evt); err != nil {
+	if _, err := d.UpsertValidatorPayoutEvent(validator.ID, evt); err != nil {
 		return errors.Wrapf(err, "error upserting validator payout event")
 	}
 	return nil
 }
 
+// blocks a handled tx is remembered for, subscriptions deliver a tx right after its block
+const seenTxsRetention = 10
+
 func (a *IndexerApp) consumeEvents(clients []*tmclient.HTTP) error {
 	// splitting across multiple tendermint clients as websocket allows max of 5 subscriptions per client
 	blockEvents := subscribe(clients[0], "tm.event = 'NewBlock'")
@@ -93,10 +106,17 @@ func (a *IndexerApp) consumeEvents(clients []*tmclient.HTTP) error {
 	openContractEvents := subscribe(clients[1], "tm.event = 'Tx' AND message.action='/arkeo.arkeo.MsgOpenContract'")
 	closeContractEvents := subscribe(clients[1], "tm.event = 'Tx' AND message.action='/arkeo.arkeo.MsgCloseContract'")
 	claimContractIncomeEvents := subscribe(clients[1], "tm.event = 'Tx' AND message.action='/arkeo.arkeo.MsgClaimContractIncome'")
+	// arkeo messages executed by an authz grantee
+	execEvents := subscribe(clients[0], "tm.event = 'Tx' AND message.action='/cosmos.authz.v1beta1.MsgExec'")
+	// failed txs emit no message events so are only seen among every tx, successful ones among them are seen already
+	txEvents := subscribe(clients[2], "tm.event = 'Tx'")
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
+	// tx hash to height of txs handled, a tx matching several subscriptions is delivered once for each
+	seenTxs := make(map[string]int64, 64)
+
 	log.Infof("beginning realtime event consumption")
 	for {
 		select {
@@ -108,10 +128,16 @@ func (a *IndexerApp) consumeEvents(clients []*tmclient.HTTP) error {
 			}
 			log := log.WithField("height", strconv.FormatInt(data.Block.Height, 10))
 			log.Debugf("received block: %d", data.Block.Height)
+			ctx, span := tracing.Start(context.Background(), "block", attribute.Int64("height", data.Block.Height))
 
-			if err := a.handleBlockEvent(data.Block); err != nil {
+			if err := a.handleBlockEvent(ctx, data.Block); err != nil {
 				log.Errorf("error handling block event %d: %+v", data.Block.Height, err)
 			}
+			for hash, height := range seenTxs {
+				if height < data.Block.Height-seenTxsRetention {
+					delete(seenTxs, hash)
+				}
+			}
 
 			endBlockEvents := data.ResultEndBlock.Events
 			log.Debugf("block %d with %d endBlock events", data.Block.Height, len(endBlockEvents))
@@ -119,97 +145,42 @@ func (a *IndexerApp) consumeEvents(clients []*tmclient.HTTP) error {
 				switch evt.GetType() {
 				case "validator_payout":
 					validatorPayoutEvent := types.ValidatorPayoutEvent{}
-					if err := convertEvent(tmAttributeSource(nil, evt, data.Block.Height), &validatorPayoutEvent); err != nil {
+					if err := a.decodeEvent(nil, evt, data.Block.Height, txEventSource{}, &validatorPayoutEvent); err != nil {
 						log.Errorf("error converting validator_payout event: %+v", err)
 						break
 					}
-					if err := a.handleValidatorPayoutEvent(validatorPayoutEvent); err != nil {
+					if err := observed(eventValidatorPayout, a.handleValidatorPayoutEvent(ctx, validatorPayoutEvent)); err != nil {
 						log.Errorf("error handling validator_payout event: %+v", err)
 					}
 				case "contract_settlement":
 					contractSettlementEvent := types.ContractSettlementEvent{}
-					if err := convertEvent(tmAttributeSource(nil, evt, data.Block.Height), &contractSettlementEvent); err != nil {
+					if err := a.decodeEvent(nil, evt, data.Block.Height, txEventSource{}, &contractSettlementEvent); err != nil {
 						log.Errorf("error converting contract_settlement event: %+v", err)
 						break
 					}
-					if err := a.handleContractSettlementEvent(contractSettlementEvent); err != nil {
+					if err := observed(eventContractSettlement, a.handleContractSettlementEvent(ctx, contractSettlementEvent)); err != nil {
 						log.Errorf("error handling close_contract contract_settlement event: %+v", err)
 					}
 				}
 			}
-		case evt := <-openContractEvents:
-			log.Debugf("received open contract event")
-			openContractEvent := types.OpenContractEvent{}
-			if err := convertEvent(wsAttributeSource(evt), &openContractEvent); err != nil {
-				log.Errorf("error converting open_contract event: %+v", err)
-				break
-			}
-			if err := a.handleOpenContractEvent(openContractEvent); err != nil {
-				log.Errorf("error handling open_contract event: %+v", err)
-			}
+			span.End()
 		case evt := <-bondProviderEvents:
-			log.Debugf("received bond provider event")
-			bondProviderEvent := types.BondProviderEvent{}
-			if err := convertEvent(wsAttributeSource(evt), &bondProviderEvent); err != nil {
-				log.Errorf("error converting bond_provider event: %+v", err)
-				break
-			}
-			if err := a.handleBondProviderEvent(bondProviderEvent); err != nil {
-				log.Errorf("error handling bond_provider event: %+v", err)
-			}
+			a.handleTxResult(evt, seenTxs)
 		case evt := <-modProviderEvents:
-			log.Debugf("received mod provider event")
-			modProviderEvent := types.ModProviderEvent{}
-			if err := convertEvent(wsAttributeSource(evt), &modProviderEvent); err != nil {
-				log.Errorf("error converting mod_provider event: %+v", err)
-				break
-			}
-			if err := a.handleModProviderEvent(modProviderEvent); err != nil {
-				log.Errorf("error handling mod_provider event: %+v", err)
-			}
-		case evt := <-claimContractIncomeEvents:
-			log.Debugf("received claim contract income event")
-			claimContractIncomeEvent := types.ClaimContractIncomeEvent{}
-			attribs := wsAttributeSource(evt)
-			// hack contract_settlement height
-			wrapped := func() map[string]string {
-				tmp := attribs()
-				if ev, ok := evt.Events["contract_settlement.height"]; ok && len(ev) > 0 {
-					tmp["height"] = evt.Events["contract_settlement.height"][0]
-				}
-				return tmp
-			}
-			if err := convertEvent(wrapped, &claimContractIncomeEvent); err != nil {
-				log.Errorf("error converting open_contract event: %+v", err)
-				break
-			}
-			if err := a.handleContractSettlementEvent(claimContractIncomeEvent.ContractSettlementEvent); err != nil {
-				log.Errorf("error handling claim contract income event: %+v", err)
-			}
+			a.handleTxResult(evt, seenTxs)
+		case evt := <-openContractEvents:
+			a.handleTxResult(evt, seenTxs)
 		case evt := <-closeContractEvents:
-			log.Debugf("received close_contract event")
-			closeContractEvent := types.CloseContractEvent{}
-			attribs := wsAttributeSource(evt)
-			wrapped := func() map[string]string {
-				tmp := attribs()
-				if ev, ok := evt.Events["contract_settlement.height"]; ok && len(ev) > 0 {
-					tmp["height"] = evt.Events["contract_settlement.height"][0]
-				}
-				return tmp
-			}
-
-			if err := convertEvent(wrapped, &closeContractEvent); err != nil {
-				log.Errorf("error converting close_contract event: %+v", err)
-				break
-			}
-
-			if err := a.handleCloseContractEvent(closeContractEvent); err != nil {
-				log.Errorf("error handling close_contract event: %+v", err)
-			}
-
-			if err := a.handleContractSettlementEvent(closeContractEvent.ContractSettlementEvent); err != nil {
-				log.Errorf("error handling close_contract contract_settlement event: %+v", err)
+			a.handleTxResult(evt, seenTxs)
+		case evt := <-claimContractIncomeEvents:
+			a.handleTxResult(evt, seenTxs)
+		case evt := <-execEvents:
+			a.handleTxResult(evt, seenTxs)
+		case evt := <-txEvents:
+			if data, ok := evt.Data.(tmtypes.EventDataTx); ok && data.Result.Code == 0 {
+				continue
 			}
+			a.handleTxResult(evt, seenTxs)
 		case <-quit:
 			log.Infof("received os quit signal")
 			return nil
@@ -218,6 +189,8 @@ func (a *IndexerApp) consumeEvents(clients []*tmclient.HTTP) error {
 }
 
 func (a *IndexerApp) consumeHistoricalBlock(client *tmclient.HTTP, bheight int64) (result *db.Block, err error) {
+	ctx, span := tracing.Start(context.Background(), "block", attribute.Int64("height", bheight))
+	defer func() { tracing.End(span, err) }()
 
 	wg := sync.WaitGroup{}
 	wg.Add(2)
@@ -229,7 +202,8 @@ func (a *IndexerApp) consumeHistoricalBlock(client *tmclient.HTTP, bheight int64
 	go func() {
 		defer wg.Done()
 		start := time.Now()
-		block, blockErr = client.Block(context.Background(), &bheight)
+		block, blockErr = client.Block(ctx, &bheight)
+		metrics.ObserveRPC("Block", start, blockErr)
 		if time.Since(start) > 500*time.Millisecond {
 			log.Warnf("%.3f elapsed reading block %d", time.Since(start).Seconds(), bheight)
 		}
@@ -238,7 +212,8 @@ func (a *IndexerApp) consumeHistoricalBlock(client *tmclient.HTTP, bheight int64
 	go func() {
 		defer wg.Done()
 		start := time.Now()
-		blockResults, resultsErr = client.BlockResults(context.Background(), &bheight)
+		blockResults, resultsErr = client.BlockResults(ctx, &bheight)
+		metrics.ObserveRPC("BlockResults", start, resultsErr)
 		if time.Since(start) > 500*time.Millisecond {
 			log.Warnf("%.3f elapsed reading block results %d", time.Since(start).Seconds(), bheight)
 		}
@@ -254,23 +229,20 @@ func (a *IndexerApp) consumeHistoricalBlock(client *tmclient.HTTP, bheight int64
 
 	log := log.WithField("height", strconv.FormatInt(block.Block.Height, 10))
 	for _, transaction := range block.Block.Txs {
-		txInfo, err := client.Tx(context.Background(), transaction.Hash(), false)
+		start := time.Now()
+		txInfo, err := client.Tx(ctx, transaction.Hash(), false)
+		metrics.ObserveRPC("Tx", start, err)
 		if err != nil {
 			log.Warnf("failed to get transaction data for %s", transaction.Hash())
 			continue
 		}
 
-		for _, event := range txInfo.TxResult.Events {
-			log.Debugf("received %s txevent", event.Type)
-			if err := a.handleAbciEvent(event, transaction, block.Block.Height); err != nil {
-				log.Errorf("error handling abci event %#v\n%+v", event, err)
-			}
-		}
+		a.handleTxEvents(ctx, transaction, txInfo.TxResult, block.Block.Height)
 	}
 
 	for _, event := range blockResults.EndBlockEvents {
 		log.Debugf("received %s endblock event", event.Type)
-		if err := a.handleAbciEvent(event, nil, block.Block.Height); err != nil {
+		if err := a.handleAbciEvent(ctx, event, nil, block.Block.Height, txEventSource{}); err != nil {
 			log.Errorf("error handling abci event %#v\n%+v", event, err)
 		}
 	}
@@ -283,71 +255,162 @@ func (a *IndexerApp) consumeHistoricalBlock(client *tmclient.HTTP, bheight int64
 	return r, nil
 }
 
-func (a *IndexerApp) handleAbciEvent(event abcitypes.Event, transaction tmtypes.Tx, height int64) error {
+// handleTxResult handles the events of a tx delivered by a realtime subscription unless seen already
+func (a *IndexerApp) handleTxResult(evt ctypes.ResultEvent, seen map[string]int64) {
+	data, ok := evt.Data.(tmtypes.EventDataTx)
+	if !ok {
+		log.Errorf("event not tx: %T", evt.Data)
+		return
+	}
+	transaction := tmtypes.Tx(data.Tx)
+	hash := strings.ToUpper(hex.EncodeToString(transaction.Hash()))
+	if _, ok = seen[hash]; ok {
+		return
+	}
+	seen[hash] = data.Height
+	log.Debugf("received tx %s", hash)
+
+	ctx, span := tracing.Start(context.Background(), "tx", attribute.String("hash", hash), attribute.Int64("height", data.Height))
+	defer span.End()
+	a.handleTxEvents(ctx, transaction, data.Result, data.Height)
+}
+
+// handleTxEvents handles each of a tx's events in order with its position in the tx and the signer of the message
+// emitting it. txs carrying an arkeo event are recorded with their fee and gas. a failed tx changed no state, its
+// events are not handled and it is recorded with its arkeo messages if it has any
+func (a *IndexerApp) handleTxEvents(ctx context.Context, transaction tmtypes.Tx, result abcitypes.ResponseDeliverTx, height int64) {
+	hash := strings.ToUpper(hex.EncodeToString(transaction.Hash()))
+	tx, err := a.decodeTx(transaction)
+	if err != nil {
+		log.Warnf("handling events of tx %s without signers: %+v", hash, err)
+	}
+	if result.Code != 0 {
+		a.handleFailedTx(ctx, hash, height, tx, result)
+		return
+	}
+	events := result.Events
+	for _, event := range events {
+		if isArkeoEvent(event.Type) {
+			if _, err = a.db.WithContext(ctx).UpsertTx(txRecord(hash, height, tx, result)); err != nil {
+				log.Errorf("error upserting tx %s: %+v", hash, err)
+			}
+			break
+		}
+	}
+	for i, index := range txEventIndices(events) {
+		event := events[i]
+		log.Debugf("received %s txevent", event.Type)
+		src := txEventSource{index: index, signer: tx.signerOf(index.MsgIndex, event)}
+		if err := a.handleAbciEvent(ctx, event, transaction, height, src); err != nil {
+			log.Errorf("error handling abci event %#v\n%+v", event, err)
+		}
+	}
+}
+
+// handleFailedTx records a failed tx and its arkeo messages, the fee of a failed tx is still paid
+func (a *IndexerApp) handleFailedTx(ctx context.Context, hash string, height int64, tx *decodedTx, result abcitypes.ResponseDeliverTx) {
+	records := a.failedTxRecords(hash, height, tx, result)
+	if len(records) == 0 {
+		return
+	}
+	log.Debugf("received failed tx %s code %d", hash, result.Code)
+	if _, err := a.db.WithContext(ctx).UpsertTx(txRecord(hash, height, tx, result)); err != nil {
+		log.Errorf("error upserting tx %s: %+v", hash, err)
+	}
+	for _, record := range records {
+		if _, err := a.db.WithContext(ctx).UpsertFailedTx(record); err != nil {
+			log.Errorf("error upserting failed tx %s message %d/%d: %+v", hash, record.MsgIndex, record.ExecIndex, err)
+		}
+	}
+}
+
+// txEventIndices gives the position of each of a tx's events. baseapp emits a message event carrying the msg's action
+// ahead of each message's own events, those before the first are the ante handler's and counted as the first msg's
+func txEventIndices(events []abcitypes.Event) []types.TxEventIndex {
+	indices := make([]types.TxEventIndex, len(events))
+	msgIndex := -1
+	for i, event := range events {
+		if event.Type == sdk.EventTypeMessage {
+			for _, attr := range event.Attributes {
+				if string(attr.Key) == sdk.AttributeKeyAction {
+					msgIndex++
+					break
+				}
+			}
+		}
+		indices[i] = types.TxEventIndex{MsgIndex: msgIndex, EventIndex: i}
+		if msgIndex < 0 {
+			indices[i].MsgIndex = 0
+		}
+	}
+	return indices
+}
+
+func (a *IndexerApp) handleAbciEvent(ctx context.Context, event abcitypes.Event, transaction tmtypes.Tx, height int64, src txEventSource) error {
 	var err error
 	switch event.Type {
 	case "provider_bond":
 		bondProviderEvent := types.BondProviderEvent{}
-		if err = convertEvent(tmAttributeSource(transaction, event, height), &bondProviderEvent); err != nil {
+		if err = a.decodeEvent(transaction, event, height, src, &bondProviderEvent); err != nil {
 			log.Errorf("error converting %s event: %+v", event.Type, err)
 			break
 		}
-		if err = a.handleBondProviderEvent(bondProviderEvent); err != nil {
+		if err = observed(eventBondProvider, a.handleBondProviderEvent(ctx, bondProviderEvent)); err != nil {
 			log.Errorf("error handling %s event: %+v", event.Type, err)
 		}
 	case "provider_mod":
 		modProviderEvent := types.ModProviderEvent{}
-		if err = convertEvent(tmAttributeSource(transaction, event, height), &modProviderEvent); err != nil {
+		if err = a.decodeEvent(transaction, event, height, src, &modProviderEvent); err != nil {
 			log.Errorf("error converting %s event: %+v", event.Type, err)
 			break
 		}
-		if err = a.handleModProviderEvent(modProviderEvent); err != nil {
+		if err = observed(eventModProvider, a.handleModProviderEvent(ctx, modProviderEvent)); err != nil {
 			log.Errorf("error handling %s event: %+v", event.Type, err)
 		}
 	case "open_contract":
 		openContractEvent := types.OpenContractEvent{}
-		if err := convertEvent(tmAttributeSource(transaction, event, height), &openContractEvent); err != nil {
+		if err := a.decodeEvent(transaction, event, height, src, &openContractEvent); err != nil {
 			log.Errorf("error converting %s event: %+v", event.Type, err)
 			break
 		}
-		if err = a.handleOpenContractEvent(openContractEvent); err != nil {
+		if err = observed(eventOpenContract, a.handleOpenContractEvent(ctx, openContractEvent)); err != nil {
 			log.Errorf("error handling %s event: %+v", event.Type, err)
 		}
 	case "claim_contract_income":
 		contractSettlementEvent := types.ContractSettlementEvent{}
-		if err := convertEvent(tmAttributeSource(transaction, event, height), &contractSettlementEvent); err != nil {
+		if err := a.decodeEvent(transaction, event, height, src, &contractSettlementEvent); err != nil {
 			log.Errorf("error converting claim_contract_income event: %+v", err)
 			break
 		}
-		if err := a.handleContractSettlementEvent(contractSettlementEvent); err != nil {
+		if err := observed(eventContractSettlement, a.handleContractSettlementEvent(ctx, contractSettlementEvent)); err != nil {
 			log.Errorf("error handling claim contract income event: %+v", err)
 		}
 	case "validator_payout":
 		validatorPayoutEvent := types.ValidatorPayoutEvent{}
-		if err := convertEvent(tmAttributeSource(transaction, event, height), &validatorPayoutEvent); err != nil {
+		if err := a.decodeEvent(transaction, event, height, src, &validatorPayoutEvent); err != nil {
 			log.Errorf("error converting validatorPayoutEvent event: %+v", err)
 			break
 		}
-		if err := a.handleValidatorPayoutEvent(validatorPayoutEvent); err != nil {
+		if err := observed(eventValidatorPayout, a.handleValidatorPayoutEvent(ctx, validatorPayoutEvent)); err != nil {
 			log.Errorf("error handling claim contract income event: %+v", err)
 		}
 	case "contract_settlement":
 		contractSettlementEvent := types.ContractSettlementEvent{}
-		if err := convertEvent(tmAttributeSource(transaction, event, height), &contractSettlementEvent); err != nil {
+		if err := a.decodeEvent(transaction, event, height, src, &contractSettlementEvent); err != nil {
 			log.Errorf("error converting contractSettlementEvent: %+v", err)
 			break
 		}
-		if err := a.handleContractSettlementEvent(contractSettlementEvent); err != nil {
+		if err := observed(eventContractSettlement, a.handleContractSettlementEvent(ctx, contractSettlementEvent)); err != nil {
 			log.Errorf("error handling contractSettlementEvent: %+v", err)
 		}
 	case "close_contract":
 		log.Debugf("received close_contract event")
 		closeContractEvent := types.CloseContractEvent{}
-		if err := convertEvent(tmAttributeSource(transaction, event, height), &closeContractEvent); err != nil {
+		if err := a.decodeEvent(transaction, event, height, src, &closeContractEvent); err != nil {
 			log.Errorf("error converting close_contract event: %+v", err)
 			break
 		}
-		if err := a.handleCloseContractEvent(closeContractEvent); err != nil {
+		if err := observed(eventCloseContract, a.handleCloseContractEvent(ctx, closeContractEvent)); err != nil {
 			log.Errorf("error handling close contract event: %+v", err)
 		}
 	default:
@@ -358,7 +421,18 @@ func (a *IndexerApp) handleAbciEvent(event abcitypes.Event, transaction tmtypes.
 
 // copy attributes of map given by attributeFunc() to target which must be a pointer (map/slice implicitly ptr)
 func convertEvent(attributeFunc attributes, target interface{}) error {
-	return mapstructure.WeakDecode(attributeFunc(), target)
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       types.AmountDecodeHook,
+		WeaklyTypedInput: true,
+		Result:           target,
+	})
+	if err == nil {
+		err = decoder.Decode(attributeFunc())
+	}
+	if err != nil {
+		metrics.ObserveEvent(eventType(target), "decode", err)
+	}
+	return err
 }
 
 func subscribe(client *tmclient.HTTP, query string) <-chan ctypes.ResultEvent {
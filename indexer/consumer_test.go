@@ -0,0 +1,57 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/arkeonetwork/directory/pkg/types"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestTxEventIndices(t *testing.T) {
+	event := func(typ string, attrs ...string) abcitypes.Event {
+		evt := abcitypes.Event{Type: typ}
+		for i := 0; i+1 < len(attrs); i += 2 {
+			evt.Attributes = append(evt.Attributes, abcitypes.EventAttribute{Key: []byte(attrs[i]), Value: []byte(attrs[i+1])})
+		}
+		return evt
+	}
+	events := []abcitypes.Event{
+		// ante handler
+		event("tx", "fee", "200uarkeo"),
+		event("message", "sender", "tarkeo1x"),
+		// two open contract msgs in one tx
+		event("message", "action", "/arkeo.arkeo.MsgOpenContract"),
+		event("open_contract", "provider", "p1"),
+		event("message", "action", "/arkeo.arkeo.MsgOpenContract"),
+		event("coin_spent", "amount", "10uarkeo"),
+		event("open_contract", "provider", "p2"),
+	}
+	expected := []types.TxEventIndex{
+		{MsgIndex: 0, EventIndex: 0},
+		{MsgIndex: 0, EventIndex: 1},
+		{MsgIndex: 0, EventIndex: 2},
+		{MsgIndex: 0, EventIndex: 3},
+		{MsgIndex: 1, EventIndex: 4},
+		{MsgIndex: 1, EventIndex: 5},
+		{MsgIndex: 1, EventIndex: 6},
+	}
+	indices := txEventIndices(events)
+	if len(indices) != len(expected) {
+		t.Fatalf("expected %d indices got %d", len(expected), len(indices))
+	}
+	for i := range expected {
+		if indices[i] != expected[i] {
+			t.Errorf("event %d: expected %+v got %+v", i, expected[i], indices[i])
+		}
+	}
+
+	// the position and signer are carried through to converted events
+	evt := types.OpenContractEvent{}
+	src := txEventSource{index: indices[6], signer: types.TxSigner{Signer: "tarkeo1s", FeePayer: "tarkeo1f", Memo: "m"}}
+	if err := convertEvent(tmAttributeSource(nil, events[6], 10, src), &evt); err != nil {
+		t.Fatalf("error converting event: %+v", err)
+	}
+	if evt.MsgIndex != 1 || evt.EventIndex != 6 || evt.ProviderPubkey != "p2" || evt.TxSigner != src.signer {
+		t.Errorf("unexpected converted event %+v", evt)
+	}
+}
@@ -1,63 +1,158 @@
 package indexer
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/tracing"
 	"github.com/arkeonetwork/directory/pkg/types"
 	"github.com/pkg/errors"
 )
 
-func (a *IndexerApp) handleOpenContractEvent(evt types.OpenContractEvent) error {
-	provider, err := a.db.FindProvider(evt.ProviderPubkey, evt.Chain)
+func (a *IndexerApp) handleOpenContractEvent(ctx context.Context, evt types.OpenContractEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "handleOpenContractEvent")
+	defer func() { tracing.End(span, err) }()
+	d := a.db.WithContext(ctx)
+
+	provider, err := d.FindProvider(evt.ProviderPubkey, evt.Chain)
 	if err != nil {
 		return errors.Wrapf(err, "error finding provider %s for chain %s", evt.ProviderPubkey, evt.Chain)
 	}
 	if provider == nil {
 		return fmt.Errorf("no provider found: DNE %s %s", evt.ProviderPubkey, evt.Chain)
 	}
-	ent, err := a.db.UpsertContract(provider.ID, evt)
+	ent, err := d.UpsertContract(provider.ID, evt)
 	if err != nil {
 		return errors.Wrapf(err, "error upserting contract")
 	}
-	if _, err = a.db.UpsertOpenContractEvent(ent.ID, evt); err != nil {
+	for _, pubkey := range []string{evt.ClientPubkey, evt.DelegatePubkey} {
+		if err = a.recordAddress(d, pubkey); err != nil {
+			log.Warnf("contract %d indexed without address for %s: %+v", ent.ID, pubkey, err)
+		}
+	}
+	if _, err = d.UpsertOpenContractEvent(ent.ID, evt); err != nil {
 		return errors.Wrapf(err, "error upserting open contract event")
 	}
 
 	return nil
 }
 
-func (a *IndexerApp) handleCloseContractEvent(evt types.CloseContractEvent) error {
-	contracts, err := a.db.FindContractsByPubKeys(evt.Chain, evt.ProviderPubkey, evt.GetDelegatePubkey())
+func (a *IndexerApp) handleCloseContractEvent(ctx context.Context, evt types.CloseContractEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "handleCloseContractEvent")
+	defer func() { tracing.End(span, err) }()
+	d := a.db.WithContext(ctx)
+
+	contract, err := resolveContract(d, evt.BaseContractEvent)
 	if err != nil {
-		return errors.Wrapf(err, "error finding contract for %s:%s %s", evt.ProviderPubkey, evt.Chain, evt.GetDelegatePubkey())
-	}
-	if len(contracts) < 1 {
-		return fmt.Errorf("no contracts found: %s:%s %s", evt.ProviderPubkey, evt.Chain, evt.GetDelegatePubkey())
+		return errors.Wrapf(err, "error resolving contract of close event %s", evt.TxID)
 	}
-
-	// FindContractsByPubKeys returns by id descending (newest)
-	contract := contracts[0]
-	if _, err = a.db.UpsertCloseContractEvent(contract.ID, evt); err != nil {
+	if _, err = d.UpsertCloseContractEvent(contract.ID, evt); err != nil {
 		return errors.Wrapf(err, "error upserting open contract event")
 	}
 
-	if _, err = a.db.CloseContract(contract.ID, evt.EventHeight); err != nil {
+	if _, err = d.CloseContract(contract.ID, evt.EventHeight); err != nil {
 		return errors.Wrapf(err, "error closing contract %d", contract.ID)
 	}
 	return nil
 }
 
-func (a *IndexerApp) handleContractSettlementEvent(evt types.ContractSettlementEvent) error {
+func (a *IndexerApp) handleContractSettlementEvent(ctx context.Context, evt types.ContractSettlementEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "handleContractSettlementEvent")
+	defer func() { tracing.End(span, err) }()
+	d := a.db.WithContext(ctx)
+
 	log.Infof("receieved contractSettlementEvent %#v", evt)
-	contract, err := a.db.FindContractByPubKeys(evt.Chain, evt.ProviderPubkey, evt.GetDelegatePubkey(), evt.Height)
+	contract, err := resolveContract(d, evt.BaseContractEvent)
 	if err != nil {
-		return errors.Wrapf(err, "error finding contract provider %s chain %s", evt.ProviderPubkey, evt.Chain)
-	}
-	if contract == nil {
-		return fmt.Errorf("no contract found for provider %s:%s delegPub: %s height %d", evt.ProviderPubkey, evt.Chain, evt.GetDelegatePubkey(), evt.Height)
+		return errors.Wrapf(err, "error resolving contract of settlement event %s", evt.TxID)
 	}
-	if _, err = a.db.UpsertContractSettlementEvent(contract.ID, evt); err != nil {
+	if _, err = d.UpsertContractSettlementEvent(contract.ID, evt); err != nil {
 		return errors.Wrapf(err, "error upserting contract settlement event")
 	}
 	return nil
 }
+
+var (
+	errContractNotFound  = errors.New("no contract found")
+	errContractAmbiguous = errors.New("contract is ambiguous")
+	errContractMismatch  = errors.New("contract does not match event")
+)
+
+// resolveContract finds the indexed contract a close, settlement or claim event refers to. the chain's contract id
+// identifies it when the event carries one, events without it are matched by provider, delegate and height. a
+// contract found by pubkeys is given the event's contract id so later events resolve by it
+func resolveContract(d *db.DirectoryDB, evt types.BaseContractEvent) (*db.ArkeoContract, error) {
+	if evt.ContractID > 0 {
+		contract, err := d.FindContractByContractID(evt.ContractID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error finding contract %d", evt.ContractID)
+		}
+		if contract != nil {
+			if contract.DelegatePubkey != evt.GetDelegatePubkey() {
+				return nil, errors.Wrapf(errContractMismatch, "contract %d has delegate %s, event has %s",
+					evt.ContractID, contract.DelegatePubkey, evt.GetDelegatePubkey())
+			}
+			return contract, nil
+		}
+	}
+
+	candidates, err := d.FindContractsByPubKeys(evt.Chain, evt.ProviderPubkey, evt.GetDelegatePubkey())
+	if err != nil {
+		return nil, errors.Wrapf(err, "error finding contracts for %s:%s %s", evt.ProviderPubkey, evt.Chain, evt.GetDelegatePubkey())
+	}
+	contract, err := matchContract(candidates, evt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s:%s %s height %d", evt.ProviderPubkey, evt.Chain, evt.GetDelegatePubkey(), evt.Height)
+	}
+	if evt.ContractID > 0 {
+		if contract.ContractID > 0 {
+			return nil, errors.Wrapf(errContractMismatch, "contract %d matched by pubkeys has contract id %d, event has %d",
+				contract.ID, contract.ContractID, evt.ContractID)
+		}
+		if _, err = d.SetContractID(contract.ID, evt.ContractID); err != nil {
+			return nil, errors.Wrapf(err, "error setting contract id of %d", contract.ID)
+		}
+		contract.ContractID = evt.ContractID
+	}
+	return contract, nil
+}
+
+// matchContract picks the contract of a provider and delegate an event refers to. settlement events carry the height
+// the contract was opened at which identifies it, otherwise it's the contract open at the event's height or, failing
+// that, the one still unclosed. several candidates are reported as ambiguous rather than guessed between
+func matchContract(candidates []*db.ArkeoContract, evt types.BaseContractEvent) (*db.ArkeoContract, error) {
+	if len(candidates) == 0 {
+		return nil, errContractNotFound
+	}
+	for _, c := range candidates {
+		if c.Height == evt.Height {
+			return c, nil
+		}
+	}
+
+	open := make([]*db.ArkeoContract, 0, 1)
+	unclosed := make([]*db.ArkeoContract, 0, 1)
+	for _, c := range candidates {
+		if c.Height > evt.EventHeight || (c.ClosedHeight > 0 && c.ClosedHeight < evt.EventHeight) {
+			continue
+		}
+		if c.ClosedHeight == 0 {
+			unclosed = append(unclosed, c)
+		}
+		if evt.EventHeight < c.Height+c.Duration {
+			open = append(open, c)
+		}
+	}
+	for _, matches := range [][]*db.ArkeoContract{open, unclosed} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		default:
+			return nil, errors.Wrapf(errContractAmbiguous, "%d contracts at height %d", len(matches), evt.EventHeight)
+		}
+	}
+	return nil, errors.Wrapf(errContractNotFound, "none of %d contracts at height %d", len(candidates), evt.EventHeight)
+}
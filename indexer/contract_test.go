@@ -0,0 +1,54 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/pkg/errors"
+)
+
+func TestMatchContract(t *testing.T) {
+	contract := func(id, height, duration, closed int64) *db.ArkeoContract {
+		return &db.ArkeoContract{Entity: db.Entity{ID: id}, Height: height, Duration: duration, ClosedHeight: closed}
+	}
+	event := func(height, eventHeight int64) types.BaseContractEvent {
+		return types.BaseContractEvent{Height: height, EventHeight: eventHeight}
+	}
+	candidates := []*db.ArkeoContract{
+		contract(3, 300, 100, 0),
+		contract(2, 200, 50, 220),
+		contract(1, 100, 50, 0),
+	}
+
+	tests := []struct {
+		name     string
+		evt      types.BaseContractEvent
+		expected int64
+		err      error
+	}{
+		{"open height", event(200, 500), 2, nil},
+		{"open at event height", event(0, 350), 3, nil},
+		// contract 1 expired without closing
+		{"unclosed", event(0, 250), 1, nil},
+		{"before any", event(0, 50), 0, errContractNotFound},
+	}
+	for _, tc := range tests {
+		c, err := matchContract(candidates, tc.evt)
+		if errors.Cause(err) != tc.err {
+			t.Errorf("%s: expected error %v got %v", tc.name, tc.err, err)
+			continue
+		}
+		if tc.err == nil && c.ID != tc.expected {
+			t.Errorf("%s: expected contract %d got %d", tc.name, tc.expected, c.ID)
+		}
+	}
+
+	overlapping := []*db.ArkeoContract{contract(2, 120, 100, 0), contract(1, 100, 100, 0)}
+	if _, err := matchContract(overlapping, event(0, 150)); errors.Cause(err) != errContractAmbiguous {
+		t.Errorf("expected overlapping contracts to be ambiguous, got %v", err)
+	}
+	if _, err := matchContract(nil, event(100, 150)); errors.Cause(err) != errContractNotFound {
+		t.Errorf("expected no candidates to be not found, got %v", err)
+	}
+}
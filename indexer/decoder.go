@@ -0,0 +1,177 @@
+package indexer
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/arkeonetwork/directory/pkg/metrics"
+	"github.com/pkg/errors"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+var errMissingAttribute = errors.New("missing required attribute")
+
+// the attributes of arkeo's events as emitted by a version of the chain. pkg/types names attributes as first
+// emitted, a later version maps the names it emits back to those
+type eventDecoder struct {
+	version string
+	// upgrade plan introducing the version, its height is read from the chain unless configured
+	upgrade string
+	// per event type, names emitted by this version to the names of pkg/types
+	renames map[string]map[string]string
+	// per event type, attributes by their pkg/types names an event can't be handled without. hash and height are
+	// filled from the tx and block when missing so are never required
+	required map[string][]string
+}
+
+// every decoder version, oldest first. a chain upgrade changing event attributes adds a version here along with
+// events of that version captured from a node to testdata/events/<version> by TestCaptureEventFixtures
+var eventDecoders = []*eventDecoder{
+	{
+		version: "v1",
+		required: map[string][]string{
+			"provider_bond": {"provider", "chain", "bond_rel"},
+			// metadata_uri isn't required, a provider can clear it
+			"provider_mod": {"pubkey", "chain", "metadata_nonce", "status", "min_contract_duration",
+				"max_contract_duration", "subscription_rate", "pay-as-you-go_rate"},
+			"open_contract":         {"provider", "chain", "client", "type", "duration", "rate"},
+			"close_contract":        {"provider", "chain", "client"},
+			"claim_contract_income": {"provider", "chain", "client", "nonce", "paid"},
+			"contract_settlement":   {"provider", "chain", "client", "nonce", "paid"},
+			"validator_payout":      {"validator", "paid"},
+		},
+	},
+}
+
+// decode renames an event's attributes to those of pkg/types and converts them to target, failing when a required
+// attribute is missing rather than leaving its field zero. a present but empty attribute is left to convert
+func (d *eventDecoder) decode(typ string, attribs map[string]string, target interface{}) error {
+	for from, to := range d.renames[typ] {
+		if value, ok := attribs[from]; ok {
+			delete(attribs, from)
+			attribs[to] = value
+		}
+	}
+	missing := make([]string, 0)
+	for _, name := range d.required[typ] {
+		if _, ok := attribs[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		err := errors.Wrapf(errMissingAttribute, "%s event as of %s: %s", typ, d.version, strings.Join(missing, ", "))
+		metrics.ObserveEvent(eventType(target), "decode", err)
+		return err
+	}
+	return convertEvent(func() map[string]string { return attribs }, target)
+}
+
+func findEventDecoder(version string) *eventDecoder {
+	for _, d := range eventDecoders {
+		if d.version == version {
+			return d
+		}
+	}
+	return nil
+}
+
+type scheduledDecoder struct {
+	from    int64
+	decoder *eventDecoder
+}
+
+// the decoder versions in effect and the heights they take effect at, ascending
+type decoderSchedule []scheduledDecoder
+
+// at gives the decoder for events emitted at height, the first version when none is scheduled
+func (s decoderSchedule) at(height int64) *eventDecoder {
+	d := eventDecoders[0]
+	for _, sd := range s {
+		if height >= sd.from {
+			d = sd.decoder
+		}
+	}
+	return d
+}
+
+// newDecoderSchedule schedules the first decoder from genesis and each later version from its height in heights.
+// versions without a height, or at 0, aren't in effect. versions must take effect in the order they're registered
+func newDecoderSchedule(heights map[string]int64) (decoderSchedule, error) {
+	for version := range heights {
+		if findEventDecoder(version) == nil {
+			return nil, errors.Errorf("no event decoder version %s", version)
+		}
+	}
+	schedule := decoderSchedule{{from: 0, decoder: eventDecoders[0]}}
+	for _, d := range eventDecoders[1:] {
+		height := heights[d.version]
+		if height <= 0 {
+			continue
+		}
+		if last := schedule[len(schedule)-1]; height <= last.from {
+			return nil, errors.Errorf("event decoder %s at %d must take effect after %s at %d", d.version, height, last.decoder.version, last.from)
+		}
+		schedule = append(schedule, scheduledDecoder{from: height, decoder: d})
+	}
+	return schedule, nil
+}
+
+// parseDecoderHeights parses comma separated version:height pairs, v2:120000,v3:480000
+func parseDecoderHeights(s string) (map[string]int64, error) {
+	heights := make(map[string]int64)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		version, height, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, errors.Errorf("event decoder height %s is not version:height", pair)
+		}
+		h, err := strconv.ParseInt(strings.TrimSpace(height), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing height of event decoder %s", version)
+		}
+		heights[strings.TrimSpace(version)] = h
+	}
+	return heights, nil
+}
+
+// loadDecoders schedules the decoder versions at their configured heights, or for versions introduced by an upgrade
+// plan, the height the chain applied it at
+func (a *IndexerApp) loadDecoders(ctx context.Context) error {
+	heights, err := parseDecoderHeights(a.params.EventDecoderHeights)
+	if err != nil {
+		return errors.Wrapf(err, "error parsing event decoder heights")
+	}
+	for _, d := range eventDecoders[1:] {
+		if _, ok := heights[d.version]; ok || d.upgrade == "" {
+			continue
+		}
+		if heights[d.version], err = a.arkeo.UpgradeHeight(ctx, d.upgrade); err != nil {
+			return errors.Wrapf(err, "error finding height of upgrade %s", d.upgrade)
+		}
+	}
+	schedule, err := newDecoderSchedule(heights)
+	if err != nil {
+		return errors.Wrapf(err, "error scheduling event decoders")
+	}
+	versions := make([]string, 0, len(schedule))
+	for _, sd := range schedule {
+		versions = append(versions, sd.decoder.version+"@"+strconv.FormatInt(sd.from, 10))
+	}
+	log.Infof("event decoders: %s", strings.Join(versions, ", "))
+	a.decoders = schedule
+	return nil
+}
+
+// decodeEvent converts an event with the decoder in effect at height, failing until loadDecoders has scheduled
+// the versions rather than decoding every height as the first
+func (a *IndexerApp) decodeEvent(tx tmtypes.Tx, evt abcitypes.Event, height int64, src txEventSource, target interface{}) error {
+	if a.decoders == nil {
+		return errors.Errorf("no event decoders loaded to decode %s event at %d", evt.Type, height)
+	}
+	return a.decoders.at(height).decode(evt.Type, tmAttributeSource(tx, evt, height, src)(), target)
+}
@@ -0,0 +1,271 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/pkg/errors"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	tmclient "github.com/tendermint/tendermint/rpc/client/http"
+)
+
+// an event as emitted by a version of the chain and the fields it decodes to. a fixture captured by
+// TestCaptureEventFixtures names the chain version it came from, one without was written by hand and can't show the
+// chain still emits the attributes decoded
+type eventFixture struct {
+	Source struct {
+		ChainVersion string `json:"chain_version"`
+		Node         string `json:"node"`
+	} `json:"source"`
+	Height int64 `json:"height"`
+	Event  struct {
+		Type       string `json:"type"`
+		Attributes []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"attributes"`
+	} `json:"event"`
+	Expected map[string]interface{} `json:"expected,omitempty"`
+}
+
+func (f *eventFixture) event() abcitypes.Event {
+	evt := abcitypes.Event{Type: f.Event.Type}
+	for _, attr := range f.Event.Attributes {
+		evt.Attributes = append(evt.Attributes, abcitypes.EventAttribute{Key: []byte(attr.Key), Value: []byte(attr.Value)})
+	}
+	return evt
+}
+
+func eventTarget(typ string) interface{} {
+	switch typ {
+	case "provider_bond":
+		return &types.BondProviderEvent{}
+	case "provider_mod":
+		return &types.ModProviderEvent{}
+	case "open_contract":
+		return &types.OpenContractEvent{}
+	case "close_contract":
+		return &types.CloseContractEvent{}
+	case "claim_contract_income", "contract_settlement":
+		return &types.ContractSettlementEvent{}
+	case "validator_payout":
+		return &types.ValidatorPayoutEvent{}
+	default:
+		return nil
+	}
+}
+
+func TestEventDecoderFixtures(t *testing.T) {
+	for _, d := range eventDecoders {
+		files, err := filepath.Glob(filepath.Join("testdata", "events", d.version, "*.json"))
+		if err != nil {
+			t.Fatalf("error listing fixtures: %+v", err)
+		}
+		if len(files) == 0 {
+			t.Errorf("no event fixtures for decoder %s", d.version)
+		}
+		for _, file := range files {
+			bz, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("error reading %s: %+v", file, err)
+			}
+			fixture := eventFixture{}
+			if err = json.Unmarshal(bz, &fixture); err != nil {
+				t.Fatalf("error decoding %s: %+v", file, err)
+			}
+			if fixture.Source.ChainVersion == "" {
+				t.Logf("%s: written by hand, not captured from a node", file)
+			}
+			evt := fixture.event()
+			target := eventTarget(evt.Type)
+			if target == nil {
+				t.Errorf("%s: no event type %s", file, evt.Type)
+				continue
+			}
+			if err = d.decode(evt.Type, tmAttributeSource(nil, evt, fixture.Height, txEventSource{})(), target); err != nil {
+				t.Errorf("%s: error decoding: %+v", file, err)
+				continue
+			}
+			decoded := map[string]interface{}{}
+			if bz, err = json.Marshal(target); err == nil {
+				err = json.Unmarshal(bz, &decoded)
+			}
+			if err != nil {
+				t.Fatalf("%s: error encoding decoded event: %+v", file, err)
+			}
+			for field, expected := range fixture.Expected {
+				if fmt.Sprint(decoded[field]) != fmt.Sprint(expected) {
+					t.Errorf("%s: %s expected %v got %v", file, field, expected, decoded[field])
+				}
+			}
+
+			// each required attribute missing fails decoding
+			for _, name := range d.required[evt.Type] {
+				attribs := tmAttributeSource(nil, evt, fixture.Height, txEventSource{})()
+				delete(attribs, name)
+				if err = d.decode(evt.Type, attribs, eventTarget(evt.Type)); !errors.Is(err, errMissingAttribute) {
+					t.Errorf("%s: expected missing %s to fail, got %v", file, name, err)
+				}
+			}
+		}
+	}
+}
+
+func TestDecoderSchedule(t *testing.T) {
+	registered := eventDecoders
+	defer func() { eventDecoders = registered }()
+	v2 := &eventDecoder{
+		version:  "v2",
+		renames:  map[string]map[string]string{"provider_mod": {"provider": "pubkey", "pay_as_you_go_rate": "pay-as-you-go_rate"}},
+		required: map[string][]string{"provider_mod": {"pubkey", "pay-as-you-go_rate"}},
+	}
+	eventDecoders = append(append([]*eventDecoder{}, registered...), v2)
+
+	heights, err := parseDecoderHeights(" v2:1000 ,")
+	if err != nil {
+		t.Fatalf("error parsing heights: %+v", err)
+	}
+	schedule, err := newDecoderSchedule(heights)
+	if err != nil {
+		t.Fatalf("error scheduling: %+v", err)
+	}
+	if d := schedule.at(999); d != registered[0] {
+		t.Errorf("expected %s before the upgrade got %s", registered[0].version, d.version)
+	}
+	if d := schedule.at(1000); d != v2 {
+		t.Errorf("expected v2 from the upgrade got %s", d.version)
+	}
+	if d := (decoderSchedule)(nil).at(1000); d != registered[0] {
+		t.Errorf("expected %s without a schedule got %s", registered[0].version, d.version)
+	}
+
+	// renamed attributes decode to the same fields
+	evt := abcitypes.Event{Type: "provider_mod", Attributes: []abcitypes.EventAttribute{
+		{Key: []byte("provider"), Value: []byte("tarkeopub1p")},
+		{Key: []byte("pay_as_you_go_rate"), Value: []byte("3")},
+	}}
+	mod := types.ModProviderEvent{}
+	if err = schedule.at(1000).decode(evt.Type, tmAttributeSource(nil, evt, 1000, txEventSource{})(), &mod); err != nil {
+		t.Fatalf("error decoding: %+v", err)
+	}
+	if mod.Pubkey != "tarkeopub1p" || mod.PayAsYouGoRate.String() != "3uarkeo" {
+		t.Errorf("unexpected event %+v", mod)
+	}
+	// the same event is missing v1's attributes
+	err = schedule.at(999).decode(evt.Type, tmAttributeSource(nil, evt, 999, txEventSource{})(), &types.ModProviderEvent{})
+	if !errors.Is(err, errMissingAttribute) {
+		t.Errorf("expected missing attributes decoding as v1, got %v", err)
+	}
+
+	// an app whose decoders weren't loaded doesn't fall back to the first version
+	a := &IndexerApp{}
+	if err = a.decodeEvent(nil, evt, 1000, txEventSource{}, &types.ModProviderEvent{}); err == nil {
+		t.Errorf("expected error decoding without loaded decoders")
+	}
+	a.decoders = schedule
+	if err = a.decodeEvent(nil, evt, 1000, txEventSource{}, &types.ModProviderEvent{}); err != nil {
+		t.Errorf("error decoding with loaded decoders: %+v", err)
+	}
+
+	if _, err = newDecoderSchedule(map[string]int64{"v9": 10}); err == nil {
+		t.Errorf("expected error scheduling an unknown version")
+	}
+	if _, err = newDecoderSchedule(map[string]int64{"v2": 0}); err != nil {
+		t.Errorf("expected an unapplied version to be left out, got %+v", err)
+	}
+	if _, err = parseDecoderHeights("v2=1000"); err == nil {
+		t.Errorf("expected error parsing a malformed height")
+	}
+}
+
+func TestDecodeEmptyAttribute(t *testing.T) {
+	// a provider clearing its metadata uri emits it empty
+	evt := abcitypes.Event{Type: "provider_mod"}
+	for _, name := range eventDecoders[0].required["provider_mod"] {
+		evt.Attributes = append(evt.Attributes, abcitypes.EventAttribute{Key: []byte(name), Value: []byte("1")})
+	}
+	evt.Attributes = append(evt.Attributes, abcitypes.EventAttribute{Key: []byte("metadata_uri"), Value: []byte("")})
+	mod := types.ModProviderEvent{}
+	if err := eventDecoders[0].decode(evt.Type, tmAttributeSource(nil, evt, 10, txEventSource{})(), &mod); err != nil {
+		t.Fatalf("error decoding an empty metadata uri: %+v", err)
+	}
+	if mod.MetadataURI != "" || mod.MetadataNonce != 1 {
+		t.Errorf("unexpected event %+v", mod)
+	}
+}
+
+// TestCaptureEventFixtures writes the arkeo events in the block results of ARKEO_FIXTURE_HEIGHTS, comma separated,
+// from the node at ARKEO_FIXTURE_RPC to testdata/events/<version>, one fixture per event type. version is
+// ARKEO_FIXTURE_DECODER, the latest decoder by default. the chain version recorded is the node's, so heights should
+// be from the version the node runs. expected fields are left to be filled from an explorer
+func TestCaptureEventFixtures(t *testing.T) {
+	node := os.Getenv("ARKEO_FIXTURE_RPC")
+	if node == "" {
+		t.Skip("ARKEO_FIXTURE_RPC not set")
+	}
+	version := os.Getenv("ARKEO_FIXTURE_DECODER")
+	if version == "" {
+		version = eventDecoders[len(eventDecoders)-1].version
+	}
+	if findEventDecoder(version) == nil {
+		t.Fatalf("no event decoder version %s", version)
+	}
+	client, err := tmclient.New(node, "/websocket")
+	if err != nil {
+		t.Fatalf("error creating client: %+v", err)
+	}
+	ctx := context.Background()
+	info, err := client.ABCIInfo(ctx)
+	if err != nil {
+		t.Fatalf("error reading node version: %+v", err)
+	}
+	captured := make(map[string]bool)
+	for _, h := range strings.Split(os.Getenv("ARKEO_FIXTURE_HEIGHTS"), ",") {
+		height, err := strconv.ParseInt(strings.TrimSpace(h), 10, 64)
+		if err != nil {
+			t.Fatalf("error parsing height %q: %+v", h, err)
+		}
+		results, err := client.BlockResults(ctx, &height)
+		if err != nil {
+			t.Fatalf("error reading block results at %d: %+v", height, err)
+		}
+		events := append(append([]abcitypes.Event{}, results.BeginBlockEvents...), results.EndBlockEvents...)
+		for _, tx := range results.TxsResults {
+			if tx.Code == 0 {
+				events = append(events, tx.Events...)
+			}
+		}
+		for _, evt := range events {
+			if captured[evt.Type] || eventTarget(evt.Type) == nil {
+				continue
+			}
+			fixture := eventFixture{Height: height}
+			fixture.Source.ChainVersion = info.Response.Version
+			fixture.Source.Node = node
+			fixture.Event.Type = evt.Type
+			for _, attr := range evt.Attributes {
+				fixture.Event.Attributes = append(fixture.Event.Attributes, struct {
+					Key   string `json:"key"`
+					Value string `json:"value"`
+				}{Key: string(attr.Key), Value: string(attr.Value)})
+			}
+			bz, err := json.MarshalIndent(fixture, "", "  ")
+			if err != nil {
+				t.Fatalf("error encoding %s event: %+v", evt.Type, err)
+			}
+			file := filepath.Join("testdata", "events", version, evt.Type+".json")
+			if err = os.WriteFile(file, append(bz, '\n'), 0o644); err != nil {
+				t.Fatalf("error writing %s: %+v", file, err)
+			}
+			captured[evt.Type] = true
+			t.Logf("captured %s at %d from %s", evt.Type, height, info.Response.Version)
+		}
+	}
+}
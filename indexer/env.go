@@ -0,0 +1,124 @@
+package indexer
+
+import (
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/config"
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/tracing"
+	"github.com/pkg/errors"
+)
+
+// Config is the env configuration shared by cmd/indexer and cmd/directory
+type Config struct {
+	ArkeoApi            string        `mapstructure:"ARKEO_API"`
+	TendermintApi       string        `mapstructure:"TENDERMINT_API"`
+	TendermintWs        string        `mapstructure:"TENDERMINT_WS"`
+	ChainID             string        `mapstructure:"CHAIN_ID"`
+	IndexerID           int64         `mapstructure:"INDEXER_ID"`
+	Bech32PrefixAccAddr string        `mapstructure:"BECH32_PREF_ACC_ADDR"`
+	Bech32PrefixAccPub  string        `mapstructure:"BECH32_PREF_ACC_PUB"`
+	IPFSGateway         string        `mapstructure:"IPFS_GATEWAY"`
+	HealthListen        string        `mapstructure:"INDEXER_HEALTH_LISTEN"`
+	MaxBlockAge         time.Duration `mapstructure:"HEALTH_MAX_BLOCK_AGE"`
+	TracingExporter     string        `mapstructure:"TRACING_EXPORTER"`
+	OTLPEndpoint        string        `mapstructure:"OTLP_ENDPOINT"`
+	OTLPInsecure        bool          `mapstructure:"OTLP_INSECURE"`
+	ReconcileInterval   time.Duration `mapstructure:"RECONCILE_INTERVAL"`
+	ReconcileRepair     bool          `mapstructure:"RECONCILE_REPAIR"`
+	ChainRegistryFile   string        `mapstructure:"CHAIN_REGISTRY_FILE"`
+	EventDecoderHeights string        `mapstructure:"EVENT_DECODER_HEIGHTS"`
+	DBHost              string        `mapstructure:"DB_HOST"`
+	DBPort              uint          `mapstructure:"DB_PORT"`
+	DBUser              string        `mapstructure:"DB_USER"`
+	DBPass              string        `mapstructure:"DB_PASS"`
+	DBName              string        `mapstructure:"DB_NAME"`
+	DBSSLMode           string        `mapstructure:"DB_SSL_MODE"`
+	DBPoolMaxConns      int           `mapstructure:"DB_POOL_MAX_CONNS"`
+	DBPoolMinConns      int           `mapstructure:"DB_POOL_MIN_CONNS"`
+}
+
+var configNames = []string{
+	"ARKEO_API",
+	"TENDERMINT_API",
+	"TENDERMINT_WS",
+	"CHAIN_ID",
+	"INDEXER_ID",
+	"BECH32_PREF_ACC_ADDR",
+	"BECH32_PREF_ACC_PUB",
+	"IPFS_GATEWAY",
+	"INDEXER_HEALTH_LISTEN",
+	"HEALTH_MAX_BLOCK_AGE",
+	"TRACING_EXPORTER",
+	"OTLP_ENDPOINT",
+	"OTLP_INSECURE",
+	"RECONCILE_INTERVAL",
+	"RECONCILE_REPAIR",
+	"CHAIN_REGISTRY_FILE",
+	"EVENT_DECODER_HEIGHTS",
+	"DB_HOST",
+	"DB_PORT",
+	"DB_USER",
+	"DB_PASS",
+	"DB_NAME",
+	"DB_SSL_MODE",
+	"DB_POOL_MAX_CONNS",
+	"DB_POOL_MIN_CONNS",
+}
+
+// LoadConfig reads the env file at envPath, or the process environment when envPath is empty
+func LoadConfig(envPath string) (*Config, error) {
+	c := &Config{}
+	if envPath == "" {
+		if err := config.LoadFromEnv(c, configNames...); err != nil {
+			return nil, errors.Wrapf(err, "failed to load config from env")
+		}
+		return c, nil
+	}
+	if err := config.Load(envPath, c); err != nil {
+		return nil, errors.Wrapf(err, "failed to load config")
+	}
+	return c, nil
+}
+
+func (c *Config) DBConfig() db.DBConfig {
+	return db.DBConfig{
+		Host:         c.DBHost,
+		Port:         c.DBPort,
+		User:         c.DBUser,
+		Pass:         c.DBPass,
+		DBName:       c.DBName,
+		PoolMaxConns: c.DBPoolMaxConns,
+		PoolMinConns: c.DBPoolMinConns,
+		SSLMode:      c.DBSSLMode,
+	}
+}
+
+func (c *Config) AppParams() IndexerAppParams {
+	return IndexerAppParams{
+		ChainID:             c.ChainID,
+		IndexerID:           c.IndexerID,
+		Bech32PrefixAccAddr: c.Bech32PrefixAccAddr,
+		Bech32PrefixAccPub:  c.Bech32PrefixAccPub,
+		ArkeoApi:            c.ArkeoApi,
+		TendermintApi:       c.TendermintApi,
+		TendermintWs:        c.TendermintWs,
+		IPFSGateway:         c.IPFSGateway,
+		HealthListen:        c.HealthListen,
+		MaxBlockAge:         c.MaxBlockAge,
+		ReconcileInterval:   c.ReconcileInterval,
+		ReconcileRepair:     c.ReconcileRepair,
+		ChainRegistryFile:   c.ChainRegistryFile,
+		EventDecoderHeights: c.EventDecoderHeights,
+		DBConfig:            c.DBConfig(),
+	}
+}
+
+func (c *Config) TracingConfig(serviceName string) tracing.Config {
+	return tracing.Config{
+		ServiceName:  serviceName,
+		Exporter:     c.TracingExporter,
+		OTLPEndpoint: c.OTLPEndpoint,
+		OTLPInsecure: c.OTLPInsecure,
+	}
+}
@@ -0,0 +1,65 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/health"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	DefaultHealthListen = "0.0.0.0:7778"
+	DefaultMaxBlockAge  = 5 * time.Minute
+)
+
+// serveHealth exposes liveness, readiness and prometheus metrics for the indexer process
+func (a *IndexerApp) serveHealth() {
+	listen := a.params.HealthListen
+	if listen == "" {
+		listen = DefaultHealthListen
+	}
+	log.Infof("starting health service on %s", listen)
+	if err := http.ListenAndServe(listen, a.healthRouter()); err != nil {
+		log.Errorf("error from health listener: %+v", err)
+	}
+}
+
+func (a *IndexerApp) healthRouter() *mux.Router {
+	maxBlockAge := a.params.MaxBlockAge
+	if maxBlockAge <= 0 {
+		maxBlockAge = DefaultMaxBlockAge
+	}
+	checker := health.NewChecker(health.DefaultTimeout).
+		Add("db", health.DBCheck(a.db)).
+		Add("schema", health.SchemaCheck(a.db)).
+		Add("indexer", health.FreshnessCheck(a.db, maxBlockAge)).
+		Add("realtime", a.realtimeCheck(maxBlockAge))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/health", checker.ReadyHandler).Methods(http.MethodGet)
+	router.HandleFunc("/health/live", health.LiveHandler).Methods(http.MethodGet)
+	router.HandleFunc("/health/ready", checker.ReadyHandler).Methods(http.MethodGet)
+	router.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	return router
+}
+
+// realtimeCheck fails when the websocket consumer has not handled a block within maxAge,
+// the freshness check alone can be satisfied by the gap filler while realtime is stuck
+func (a *IndexerApp) realtimeCheck(maxAge time.Duration) health.Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		last := a.lastRealtimeBlock.Load()
+		if last == 0 {
+			return nil, fmt.Errorf("no realtime blocks received")
+		}
+		age := time.Since(time.Unix(0, last))
+		details := map[string]interface{}{"Height": a.Height, "AgeSeconds": int64(age.Seconds())}
+		if age > maxAge {
+			return details, fmt.Errorf("no realtime block received in %s", age.Round(time.Second))
+		}
+		return details, nil
+	}
+}
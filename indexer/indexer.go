@@ -10,7 +10,10 @@ import (
 
 	"github.com/arkeonetwork/common/logging"
 	arkutils "github.com/arkeonetwork/common/utils"
+	"github.com/arkeonetwork/directory/pkg/arkeo"
 	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/metadata"
+	"github.com/arkeonetwork/directory/pkg/metrics"
 	"github.com/pkg/errors"
 	tmlog "github.com/tendermint/tendermint/libs/log"
 	tmclient "github.com/tendermint/tendermint/rpc/client/http"
@@ -27,15 +30,40 @@ type IndexerAppParams struct {
 	Bech32PrefixAccAddr string
 	Bech32PrefixAccPub  string
 	IndexerID           int64
+	IPFSGateway         string
+	// listen address for /health and /metrics, readiness fails when the latest block is older than MaxBlockAge
+	HealthListen string
+	MaxBlockAge  time.Duration
+	// dev only: allow file:// metadata uris, and metadata and sentinels hosted on private addresses
+	AllowFileMetadata    bool
+	AllowPrivateMetadata bool
+	// how often providers and contracts are reconciled against ArkeoApi, zero disables it. With ReconcileRepair
+	// set, drift the chain's state is enough to correct is repaired
+	ReconcileInterval time.Duration
+	ReconcileRepair   bool
+	// json chain registry seeded on startup, the registry embedded in pkg/chains when empty
+	ChainRegistryFile string
+	// version:height pairs, v2:120000, of the heights event decoder versions take effect at. versions introduced
+	// by an upgrade plan default to the height the chain applied it at
+	EventDecoderHeights string
 	db.DBConfig
 }
 
 type IndexerApp struct {
 	Height   int64
 	IsSynced atomic.Bool
-	params   IndexerAppParams
-	db       *db.DirectoryDB
-	done     chan struct{}
+	// unix nanos when the last realtime block was handled
+	lastRealtimeBlock atomic.Int64
+	params            IndexerAppParams
+	db                *db.DirectoryDB
+	done              chan struct{}
+	metadataQueued    chan struct{}
+	metadataFetcher   *metadata.Fetcher
+	arkeo             *arkeo.Client
+	// decodes txs to attribute their events to the messages' signers
+	encoding *encodingConfig
+	// event decoder versions by the height they take effect at
+	decoders decoderSchedule
 }
 
 func NewIndexer(params IndexerAppParams) *IndexerApp {
@@ -43,14 +71,42 @@ func NewIndexer(params IndexerAppParams) *IndexerApp {
 	if err != nil {
 		panic(fmt.Sprintf("error connecting to the db: %+v", err))
 	}
-	return &IndexerApp{params: params, db: d}
+	fetcher := metadata.NewFetcher(metadata.FetcherConfig{
+		MaxBytes:              metadataMaxBytes,
+		IPFSGateway:           params.IPFSGateway,
+		AllowFile:             params.AllowFileMetadata,
+		AllowPrivateAddresses: params.AllowPrivateMetadata,
+	})
+	return &IndexerApp{
+		params:          params,
+		db:              d,
+		metadataQueued:  make(chan struct{}, 1),
+		metadataFetcher: fetcher,
+		arkeo:           arkeo.NewClient(params.ArkeoApi),
+		encoding:        NewEncoding(),
+	}
 }
 
 func (a *IndexerApp) Run() (done <-chan struct{}, err error) {
 	// initialize by reading all existing providers?
 	a.done = make(chan struct{})
+	if err = a.seedChains(); err != nil {
+		return nil, errors.Wrapf(err, "error seeding chain registry")
+	}
+	if err = a.backfillAddresses(); err != nil {
+		return nil, errors.Wrapf(err, "error backfilling addresses")
+	}
+	if err = a.loadDecoders(context.Background()); err != nil {
+		return nil, errors.Wrapf(err, "error loading event decoders")
+	}
 	go a.realtime()
 	go a.gapFiller()
+	go a.metadataWorker()
+	go a.prober()
+	go a.reputationWorker()
+	go a.blockTimeWorker()
+	go a.reconcileWorker()
+	go a.serveHealth()
 	return a.done, nil
 }
 
@@ -65,7 +121,11 @@ func NewTenderm1intClient(baseURL string) (*tmclient.HTTP, error) {
 	return client, nil
 }
 
-const fillThreads = 3
+const (
+	fillThreads = 3
+	// blocks filled between progress updates
+	fillProgressBatch = 100
+)
 
 var gaps []*db.BlockGap
 
@@ -89,10 +149,26 @@ func (a *IndexerApp) gapFiller() {
 			log.Panicf("error finding latest stored block: %+v", err)
 		}
 
+		start := time.Now()
 		latest, err := tm.Block(ctx, nil)
+		metrics.ObserveRPC("Block", start, err)
 		if err != nil {
 			log.Panicf("error finding latest block: %+v", err)
 		}
+		// blocks are indexed through the one before the first gap
+		var indexedHeight int64
+		if latestStored != nil {
+			indexedHeight = latestStored.Height
+		}
+		for _, g := range gaps {
+			if g.Start-1 < indexedHeight {
+				indexedHeight = g.Start - 1
+			}
+		}
+		metrics.SetHeights(indexedHeight, latest.Block.Height)
+		if _, err = a.db.UpdateIndexerTip(a.params.IndexerID, indexedHeight, latest.Block.Height); err != nil {
+			log.Errorf("error updating chain tip: %+v", err)
+		}
 
 		if latestStored == nil {
 			log.Infof("no latestStored, initializing")
@@ -104,6 +180,13 @@ func (a *IndexerApp) gapFiller() {
 
 		if len(gaps) > 0 {
 			log.Infof("have %d gaps to fill: %s", len(gaps), gaps)
+			var blocks int64
+			for _, g := range gaps {
+				blocks += g.End - g.Start + 1
+			}
+			if _, err = a.db.StartGapFill(a.params.IndexerID, blocks); err != nil {
+				log.Errorf("error recording gap fill start: %+v", err)
+			}
 			for i := range gaps {
 				workChan <- gaps[i]
 			}
@@ -133,6 +216,9 @@ func (a *IndexerApp) gapFiller() {
 			}
 			log.Infof("waiting for %d threads to complete filling %d gaps", startThreads, len(gaps))
 			wg.Wait()
+			if _, err = a.db.FinishGapFill(a.params.IndexerID); err != nil {
+				log.Errorf("error recording gap fill finish: %+v", err)
+			}
 		}
 
 		// all gaps filled, wait a minute
@@ -148,21 +234,42 @@ func (a *IndexerApp) fillGap(gap db.BlockGap) error {
 		return errors.Wrapf(err, "error creating tm client: %+v", err)
 	}
 
+	var done int64
 	for i := gap.Start; i <= gap.End; i++ {
 		log.Infof("processing %d", i)
+		done++
+		if done == fillProgressBatch {
+			a.addGapFillProgress(done)
+			done = 0
+		}
 		block, err := a.consumeHistoricalBlock(tm, i)
 		if err != nil {
 			log.Errorf("error consuming block %d: %+v", i, err)
+			metrics.GapFillBlocks.WithLabelValues(metrics.StatusError).Inc()
 			continue
 		}
 		if _, err = a.db.InsertBlock(block); err != nil {
 			log.Errorf("error inserting block %d with hash %s: %+v", block.Height, block.Hash, err)
+			metrics.GapFillBlocks.WithLabelValues(metrics.StatusError).Inc()
 			time.Sleep(time.Second)
+			continue
 		}
+		metrics.GapFillBlocks.WithLabelValues(metrics.StatusOK).Inc()
 	}
+	a.addGapFillProgress(done)
 	return nil
 }
 
+// progress counts blocks attempted, failed blocks are picked up as gaps by the next run
+func (a *IndexerApp) addGapFillProgress(done int64) {
+	if done == 0 {
+		return
+	}
+	if _, err := a.db.AddGapFillProgress(a.params.IndexerID, done); err != nil {
+		log.Errorf("error recording gap fill progress: %+v", err)
+	}
+}
+
 const numClients = 3
 
 func (a *IndexerApp) realtime() {
@@ -184,10 +291,17 @@ func (a *IndexerApp) realtime() {
 	a.done <- struct{}{}
 }
 
-func (a *IndexerApp) handleBlockEvent(block *tmtypes.Block) error {
-	if _, err := a.db.InsertBlock(&db.Block{Height: block.Height, Hash: block.Hash().String(), BlockTime: block.Time}); err != nil {
+func (a *IndexerApp) handleBlockEvent(ctx context.Context, block *tmtypes.Block) error {
+	d := a.db.WithContext(ctx)
+	if _, err := d.InsertBlock(&db.Block{Height: block.Height, Hash: block.Hash().String(), BlockTime: block.Time}); err != nil {
 		return errors.Wrapf(err, "error inserting block")
 	}
 	a.Height = block.Height
+	a.lastRealtimeBlock.Store(time.Now().UnixNano())
+	// realtime blocks are the chain tip, the indexed height is the gap filler's
+	metrics.SetChainTip(block.Height)
+	if _, err := d.UpdateChainTip(a.params.IndexerID, block.Height); err != nil {
+		return errors.Wrapf(err, "error updating chain tip")
+	}
 	return nil
 }
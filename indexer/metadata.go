@@ -0,0 +1,183 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/metadata"
+	"github.com/arkeonetwork/directory/pkg/metrics"
+	"github.com/pkg/errors"
+)
+
+const (
+	metadataPollInterval       = 15 * time.Second
+	metadataBatchSize          = 32
+	metadataMaxBytes           = 1e6
+	metadataRetryBaseDelay     = 30 * time.Second
+	metadataRetryMaxDelay      = 6 * time.Hour
+	metadataRevalidateInterval = 12 * time.Hour
+)
+
+// metadata fetch outcomes
+const (
+	metadataSuccess       = "success"
+	metadataInvalid       = "invalid" // stored, but failed schema validation
+	metadataDownloadError = "download_error"
+	metadataParseError    = "parse_error"
+	metadataDrift         = "drift"
+	metadataError         = "error"
+)
+
+// metadataWorker fetches provider metadata queued by mod events, retrying failures with backoff and
+// periodically re-fetching documents that were already retrieved
+func (a *IndexerApp) metadataWorker() {
+	log.Infof("starting metadata worker")
+	ticker := time.NewTicker(metadataPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.metadataQueued:
+		case <-ticker.C:
+		}
+		if err := a.processDueMetadata(); err != nil {
+			log.Errorf("error processing metadata fetches: %+v", err)
+		}
+	}
+}
+
+// wake the metadata worker without blocking the caller
+func (a *IndexerApp) wakeMetadataWorker() {
+	select {
+	case a.metadataQueued <- struct{}{}:
+	default:
+	}
+}
+
+// processDueMetadata fetches due metadata a batch at a time until none are left. a batch in which a result couldn't be
+// recorded leaves its fetches due, so it stops there until the next poll rather than fetching them again at once
+func (a *IndexerApp) processDueMetadata() error {
+	for {
+		due, err := a.db.FindDueMetadataFetches(metadataBatchSize)
+		if err != nil {
+			return errors.Wrapf(err, "error finding due metadata fetches")
+		}
+		unrecorded := 0
+		for _, fetch := range due {
+			// failures are recorded and retried
+			if recorded, _ := a.refreshMetadata(fetch); !recorded {
+				unrecorded++
+			}
+		}
+		if len(due) < metadataBatchSize {
+			return nil
+		}
+		if unrecorded > 0 {
+			return fmt.Errorf("%d of %d metadata fetches not recorded, waiting for the next poll", unrecorded, len(due))
+		}
+	}
+}
+
+// refreshMetadata fetches and records the result of one queued fetch, returning whether the result was recorded and
+// the fetch error if it failed
+func (a *IndexerApp) refreshMetadata(fetch *db.ProviderMetadataStatus) (bool, error) {
+	log := log.WithField("provider", strconv.FormatInt(fetch.ProviderID, 10))
+	log.Debugf("fetching metadata nonce %d from %s", fetch.MetadataNonce, fetch.MetadataURI)
+
+	outcome, err := a.fetchMetadata(fetch)
+	metrics.MetadataFetches.WithLabelValues(outcome).Inc()
+	if err != nil {
+		attempts := fetch.Attempts + 1
+		next := time.Now().Add(metadataRetryDelay(attempts))
+		log.Warnf("metadata fetch attempt %d for %s failed, retrying at %s: %v", attempts, fetch.MetadataURI, next.Format(time.RFC3339), err)
+		if _, markErr := a.db.MarkMetadataFetchFailure(fetch.ID, fetch.MetadataNonce, attempts, err.Error(), next); markErr != nil {
+			log.Errorf("error recording metadata fetch failure: %+v", markErr)
+			return false, err
+		}
+		return true, err
+	}
+
+	if _, err := a.db.MarkMetadataFetchSuccess(fetch.ID, fetch.MetadataNonce, time.Now().Add(metadataRevalidateInterval)); err != nil {
+		log.Errorf("error recording metadata fetch success: %+v", err)
+		return false, nil
+	}
+	return true, nil
+}
+
+// fetchMetadata returns the outcome recorded in the metadata fetch metrics
+func (a *IndexerApp) fetchMetadata(fetch *db.ProviderMetadataStatus) (string, error) {
+	// retries are scheduled by the worker rather than the fetcher
+	raw, err := a.metadataFetcher.Fetch(context.Background(), fetch.MetadataURI)
+	if err != nil {
+		return metadataDownloadError, errors.Wrapf(err, "error downloading metadata")
+	}
+
+	// a nonce identifies one document, content changing under it is flagged and the original kept
+	contentHash := metadata.ContentHash(raw)
+	stored, err := a.db.FindProviderMetadataContent(fetch.ProviderID, fetch.MetadataNonce)
+	if err != nil {
+		return metadataError, errors.Wrapf(err, "error finding stored metadata content")
+	}
+	if stored != nil && stored.ContentHash != nil && *stored.ContentHash != contentHash {
+		if _, err = a.db.FlagProviderMetadataDrift(fetch.ProviderID, fetch.MetadataNonce, contentHash); err != nil {
+			return metadataError, errors.Wrapf(err, "error flagging metadata drift")
+		}
+		return metadataDrift, fmt.Errorf("content drift under nonce %d, stored hash %s fetched %s", fetch.MetadataNonce, *stored.ContentHash, contentHash)
+	}
+
+	providerMetadata, validation, err := metadata.Parse(raw, fetch.Pubkey)
+	if err != nil {
+		return metadataParseError, errors.Wrapf(err, "error parsing metadata")
+	}
+	if providerMetadata == nil {
+		return metadataParseError, fmt.Errorf("nil providerMetadata for %s", fetch.MetadataURI)
+	}
+	if !validation.IsValid() {
+		log.Warnf("metadata for provider %s chain %s failed validation: %v", fetch.Pubkey, fetch.Chain, validation.Errors)
+	}
+
+	verified, err := a.verifyMetadataSignature(fetch, raw)
+	if err != nil {
+		validation.Warnings = append(validation.Warnings, fmt.Sprintf("signature: %v", err))
+	}
+
+	providerMetadata.Configuration.Nonce = int64(fetch.MetadataNonce)
+	if _, err = a.db.UpsertProviderMetadata(fetch.ProviderID, *providerMetadata, *validation, raw, contentHash, verified); err != nil {
+		return metadataError, errors.Wrapf(err, "error upserting provider metadata for %s chain %s", fetch.Pubkey, fetch.Chain)
+	}
+	if !validation.IsValid() {
+		return metadataInvalid, nil
+	}
+	return metadataSuccess, nil
+}
+
+// signatures are optional, a missing one leaves the document unverified without error. a signature that is
+// present but can't be fetched or doesn't verify is returned as an error for the caller to record
+func (a *IndexerApp) verifyMetadataSignature(fetch *db.ProviderMetadataStatus, raw []byte) (bool, error) {
+	sig, err := a.metadataFetcher.FetchSignature(context.Background(), fetch.MetadataURI)
+	if err != nil {
+		return false, errors.Wrapf(err, "error downloading signature")
+	}
+	if sig == nil {
+		return false, nil
+	}
+	if err = metadata.VerifySignature(raw, sig, fetch.Pubkey, a.params.Bech32PrefixAccPub); err != nil {
+		log.Warnf("metadata signature for provider %s chain %s failed verification: %v", fetch.Pubkey, fetch.Chain, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// exponential backoff starting at metadataRetryBaseDelay, capped at metadataRetryMaxDelay
+func metadataRetryDelay(attempts int) time.Duration {
+	delay := metadataRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= metadataRetryMaxDelay {
+			return metadataRetryMaxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,25 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetadataRetryDelay(t *testing.T) {
+	cases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{0, metadataRetryBaseDelay},
+		{1, metadataRetryBaseDelay},
+		{2, 2 * metadataRetryBaseDelay},
+		{3, 4 * metadataRetryBaseDelay},
+		{20, metadataRetryMaxDelay},
+		{1000, metadataRetryMaxDelay},
+	}
+	for _, c := range cases {
+		if d := metadataRetryDelay(c.attempts); d != c.expected {
+			t.Errorf("attempts %d: expected %s got %s", c.attempts, c.expected, d)
+		}
+	}
+}
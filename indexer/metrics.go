@@ -0,0 +1,42 @@
+package indexer
+
+import (
+	"github.com/arkeonetwork/directory/pkg/metrics"
+	"github.com/arkeonetwork/directory/pkg/types"
+)
+
+// event type labels, matching the counts reported by /indexer/status
+const (
+	eventBondProvider       = "bond_provider"
+	eventModProvider        = "mod_provider"
+	eventOpenContract       = "open_contract"
+	eventCloseContract      = "close_contract"
+	eventContractSettlement = "contract_settlement"
+	eventValidatorPayout    = "validator_payout"
+)
+
+// observed counts the result of handling an event of eventType and passes err through
+func observed(eventType string, err error) error {
+	metrics.ObserveEvent(eventType, "handle", err)
+	return err
+}
+
+// label for the event type decoded into target
+func eventType(target interface{}) string {
+	switch target.(type) {
+	case *types.BondProviderEvent:
+		return eventBondProvider
+	case *types.ModProviderEvent:
+		return eventModProvider
+	case *types.OpenContractEvent:
+		return eventOpenContract
+	case *types.CloseContractEvent:
+		return eventCloseContract
+	case *types.ContractSettlementEvent, *types.ClaimContractIncomeEvent:
+		return eventContractSettlement
+	case *types.ValidatorPayoutEvent:
+		return eventValidatorPayout
+	default:
+		return "unknown"
+	}
+}
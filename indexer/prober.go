@@ -0,0 +1,171 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/metadata"
+	"github.com/pkg/errors"
+)
+
+const (
+	probeInterval  = time.Minute
+	probeTimeout   = 5 * time.Second
+	probeThreads   = 8
+	probeRetention = 7 * 24 * time.Hour
+	// path on the sentinel probed for liveness
+	probePath = "/metadata.json"
+)
+
+// prober periodically requests each provider's sentinel, as declared by proxy_host/port in its metadata, recording
+// availability and latency. provider status is self-declared, probes show whether the sentinel is actually reachable
+func (a *IndexerApp) prober() {
+	log.Infof("starting prober")
+	client := newProbeClient(a.params.AllowPrivateMetadata)
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+	for {
+		if err := a.probeProviders(client); err != nil {
+			log.Errorf("error probing providers: %+v", err)
+		}
+		if _, err := a.db.DeleteProviderProbesBefore(time.Now().Add(-probeRetention)); err != nil {
+			log.Errorf("error pruning provider probes: %+v", err)
+		}
+		<-ticker.C
+	}
+}
+
+func (a *IndexerApp) probeProviders(client *http.Client) error {
+	targets, err := a.db.FindProbeTargets()
+	if err != nil {
+		return errors.Wrapf(err, "error finding probe targets")
+	}
+
+	work := make(chan *db.ProbeTarget)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < probeThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range work {
+				probe := probeSentinel(client, target)
+				if !probe.Success {
+					log.WithField("provider", target.Pubkey).Debugf("probe of %s failed: %s", probe.Endpoint, probe.Error)
+				}
+				if _, err := a.db.InsertProviderProbe(probe); err != nil {
+					log.Errorf("error inserting probe for provider %s chain %s: %+v", target.Pubkey, target.Chain, err)
+				}
+			}
+		}()
+	}
+	for _, target := range targets {
+		work <- target
+	}
+	close(work)
+	wg.Wait()
+	return nil
+}
+
+func probeSentinel(client *http.Client, target *db.ProbeTarget) db.ProviderProbe {
+	probe := db.ProviderProbe{ProviderID: target.ProviderID, ProbedAt: time.Now()}
+	endpoint, err := probeEndpoint(target.ProxyHost, target.Port)
+	if err != nil {
+		probe.Endpoint = target.ProxyHost
+		probe.Error = err.Error()
+		return probe
+	}
+	probe.Endpoint = endpoint
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	probe.Latency = time.Since(start)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, metadataMaxBytes))
+
+	probe.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		probe.Error = fmt.Sprintf("http status %d", resp.StatusCode)
+		return probe
+	}
+	probe.Success = true
+	return probe
+}
+
+// build the sentinel url from metadata proxy_host, which may or may not carry a scheme and port, and port
+func probeEndpoint(proxyHost, port string) (string, error) {
+	raw := strings.TrimSpace(proxyHost)
+	if raw == "" {
+		return "", fmt.Errorf("empty proxy host")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", errors.Wrapf(err, "error parsing proxy host %s", proxyHost)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("scheme %s not allowed", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("missing host in %s", proxyHost)
+	}
+	if u.Port() == "" && port != "" {
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + probePath
+	return u.String(), nil
+}
+
+// probe targets are provider controlled, like metadata uris connections to non-public addresses are refused
+func newProbeClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: probeTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if allowPrivate {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return errors.Wrapf(err, "error splitting address %s", address)
+			}
+			if ip := net.ParseIP(host); ip == nil || metadata.IsBlockedIP(ip) {
+				return fmt.Errorf("address %s not allowed", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: probeTimeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   probeTimeout,
+			ResponseHeaderTimeout: probeTimeout,
+			DisableKeepAlives:     true, // each probe measures a fresh connection
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
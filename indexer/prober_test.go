@@ -0,0 +1,59 @@
+package indexer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+)
+
+func TestProbeEndpoint(t *testing.T) {
+	cases := []struct {
+		proxyHost, port, expected string
+	}{
+		{"sentinel.example.com", "3636", "http://sentinel.example.com:3636/metadata.json"},
+		{"https://sentinel.example.com/", "", "https://sentinel.example.com/metadata.json"},
+		{"http://sentinel.example.com:8080", "3636", "http://sentinel.example.com:8080/metadata.json"},
+		{"10.0.0.1", "3636", "http://10.0.0.1:3636/metadata.json"},
+		{"[::1]", "3636", "http://[::1]:3636/metadata.json"},
+	}
+	for _, c := range cases {
+		actual, err := probeEndpoint(c.proxyHost, c.port)
+		if err != nil {
+			t.Errorf("error building endpoint for %s: %+v", c.proxyHost, err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("expected %s got %s", c.expected, actual)
+		}
+	}
+	for _, proxyHost := range []string{"", "ftp://sentinel.example.com", "http://"} {
+		if _, err := probeEndpoint(proxyHost, "3636"); err == nil {
+			t.Errorf("expected %q to be rejected", proxyHost)
+		}
+	}
+}
+
+func TestProbeSentinel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != probePath {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	target := &db.ProbeTarget{ProviderID: 1, ProxyHost: srv.URL}
+
+	probe := probeSentinel(newProbeClient(true), target)
+	if !probe.Success || probe.StatusCode != http.StatusOK || probe.Error != "" {
+		t.Errorf("expected successful probe got %+v", probe)
+	}
+
+	probe = probeSentinel(newProbeClient(false), target)
+	if probe.Success || !strings.Contains(probe.Error, "not allowed") {
+		t.Errorf("expected loopback sentinel to be refused got %+v", probe)
+	}
+}
@@ -1,18 +1,23 @@
 package indexer
 
 import (
+	"context"
 	"fmt"
-	"net/url"
 	"strconv"
 
+	"github.com/arkeonetwork/directory/pkg/chains"
 	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/tracing"
 	"github.com/arkeonetwork/directory/pkg/types"
-	"github.com/arkeonetwork/directory/pkg/utils"
 	"github.com/pkg/errors"
 )
 
-func (a *IndexerApp) handleModProviderEvent(evt types.ModProviderEvent) error {
-	provider, err := a.db.FindProvider(evt.Pubkey, evt.Chain)
+func (a *IndexerApp) handleModProviderEvent(ctx context.Context, evt types.ModProviderEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "handleModProviderEvent")
+	defer func() { tracing.End(span, err) }()
+	d := a.db.WithContext(ctx)
+
+	provider, err := d.FindProvider(evt.Pubkey, evt.Chain)
 	if err != nil {
 		return errors.Wrapf(err, "error finding provider %s for chain %s", evt.Pubkey, evt.Chain)
 	}
@@ -31,11 +36,11 @@ func (a *IndexerApp) handleModProviderEvent(evt types.ModProviderEvent) error {
 	provider.SubscriptionRate = evt.SubscriptionRate
 	provider.PayAsYouGoRate = evt.PayAsYouGoRate
 
-	if _, err = a.db.UpdateProvider(provider); err != nil {
+	if _, err = d.UpdateProvider(provider); err != nil {
 		return errors.Wrapf(err, "error updating provider for mod event %s chain %s", provider.Pubkey, provider.Chain)
 	}
 	log.Infof("updated provider %s chain %s", provider.Pubkey, provider.Chain)
-	if _, err = a.db.InsertModProviderEvent(provider.ID, evt); err != nil {
+	if _, err = d.InsertModProviderEvent(provider.ID, evt); err != nil {
 		return errors.Wrapf(err, "error inserting ModProviderEvent for %s chain %s", evt.Pubkey, evt.Chain)
 	}
 
@@ -43,59 +48,56 @@ func (a *IndexerApp) handleModProviderEvent(evt types.ModProviderEvent) error {
 		return nil
 	}
 
-	log.Debugf("updating provider metadata for provider %s", provider.Pubkey)
-	if !validateMetadataURI(provider.MetadataURI) {
-		log.Warnf("updating provider metadata for provider %s failed due to bad MetadataURI %s", provider.Pubkey, provider.MetadataURI)
-		return nil
-	}
-	providerMetadata, err := utils.DownloadProviderMetadata(provider.MetadataURI, 5, 1e6)
-	if err != nil {
-		log.Warnf("updating provider metadata for provider %s failed %v", provider.Pubkey, err)
-		return nil
-	}
-
-	if providerMetadata == nil {
-		log.Errorf("nil providerMetadata for %s", provider.MetadataURI)
+	log.Debugf("queueing metadata fetch for provider %s", provider.Pubkey)
+	if err = a.metadataFetcher.ValidateURI(provider.MetadataURI); err != nil {
+		log.Warnf("updating provider metadata for provider %s failed due to bad MetadataURI %s: %v", provider.Pubkey, provider.MetadataURI, err)
 		return nil
 	}
-
-	providerMetadata.Configuration.Nonce = int64(provider.MetadataNonce)
-	if _, err = a.db.UpsertProviderMetadata(provider.ID, *providerMetadata); err != nil {
-		return errors.Wrapf(err, "error updating provider metadta for mod event %s chain %s", provider.Pubkey, provider.Chain)
+	if _, err = d.QueueMetadataFetch(provider.ID, provider.MetadataURI, provider.MetadataNonce); err != nil {
+		return errors.Wrapf(err, "error queueing metadata fetch for %s chain %s", provider.Pubkey, provider.Chain)
 	}
+	a.wakeMetadataWorker()
 	return nil
 }
 
-func (a *IndexerApp) handleBondProviderEvent(evt types.BondProviderEvent) error {
-	provider, err := a.db.FindProvider(evt.Pubkey, evt.Chain)
+func (a *IndexerApp) handleBondProviderEvent(ctx context.Context, evt types.BondProviderEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "handleBondProviderEvent")
+	defer func() { tracing.End(span, err) }()
+	d := a.db.WithContext(ctx)
+
+	provider, err := d.FindProvider(evt.Pubkey, evt.Chain)
 	if err != nil {
 		return errors.Wrapf(err, "error finding provider %s for chain %s", evt.Pubkey, evt.Chain)
 	}
 	if provider == nil {
 		// new provider for chain, insert
-		if provider, err = a.createProvider(evt); err != nil {
+		if provider, err = a.createProvider(ctx, evt); err != nil {
 			return errors.Wrapf(err, "error creating provider %s chain %s", evt.Pubkey, evt.Chain)
 		}
 	} else {
-		if evt.BondAbsolute != "" {
+		if evt.BondAbsolute.IsSet() {
 			provider.Bond = evt.BondAbsolute
 		}
-		if _, err = a.db.UpdateProvider(provider); err != nil {
+		if _, err = d.UpdateProvider(provider); err != nil {
 			return errors.Wrapf(err, "error updating provider for bond event %s chain %s", evt.Pubkey, evt.Chain)
 		}
 	}
 
 	log.Debugf("handled bond provider event for %s chain %s", evt.Pubkey, evt.Chain)
-	if _, err = a.db.InsertBondProviderEvent(provider.ID, evt); err != nil {
+	if _, err = d.InsertBondProviderEvent(provider.ID, evt); err != nil {
 		return errors.Wrapf(err, "error inserting BondProviderEvent for %s chain %s", evt.Pubkey, evt.Chain)
 	}
 	return nil
 }
 
-func (a *IndexerApp) createProvider(evt types.BondProviderEvent) (*db.ArkeoProvider, error) {
+func (a *IndexerApp) createProvider(ctx context.Context, evt types.BondProviderEvent) (*db.ArkeoProvider, error) {
+	// the first bond against a chain adds it to the registry
+	if _, err := a.db.WithContext(ctx).RegisterObservedChain(chains.FromName(evt.Chain), evt.Height); err != nil {
+		return nil, errors.Wrapf(err, "error registering chain %s", evt.Chain)
+	}
 	// new provider for chain, insert
 	provider := &db.ArkeoProvider{Pubkey: evt.Pubkey, Chain: evt.Chain, Bond: evt.BondAbsolute}
-	entity, err := a.db.InsertProvider(provider)
+	entity, err := a.db.WithContext(ctx).InsertProvider(provider)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error inserting provider %s %s", evt.Pubkey, evt.Chain)
 	}
@@ -104,14 +106,10 @@ func (a *IndexerApp) createProvider(evt types.BondProviderEvent) (*db.ArkeoProvi
 	}
 	log.Debugf("inserted provider record %d for %s %s", entity.ID, evt.Pubkey, evt.Chain)
 	provider.Entity = *entity
-	return provider, nil
-}
-
-func validateMetadataURI(uri string) bool {
-	if _, err := url.ParseRequestURI(uri); err != nil {
-		return false
+	if err = a.recordAddress(a.db.WithContext(ctx), evt.Pubkey); err != nil {
+		log.Warnf("provider %s indexed without address: %+v", evt.Pubkey, err)
 	}
-	return true
+	return provider, nil
 }
 
 // not sure we need this, coming from the chain...
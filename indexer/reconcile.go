@@ -0,0 +1,335 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/arkeo"
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/metrics"
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	reconcileProvider = "provider"
+	reconcileContract = "contract"
+	// values reported for rows only one side has
+	reconcileMissing = "missing"
+	reconcilePresent = "present"
+)
+
+// a mismatch found by reconciliation and how to repair it, repair is nil when the directory can't be corrected
+// from the chain's state alone. mismatches of an entity corrected together share a repair
+type reconcileDiff struct {
+	mismatch *db.ReconcileMismatch
+	repair   *reconcileRepair
+}
+
+// a correction of the directory, applied once however many mismatches it repairs
+type reconcileRepair struct {
+	fn      func(d *db.DirectoryDB) error
+	applied bool
+	err     error
+}
+
+func newRepair(fn func(d *db.DirectoryDB) error) *reconcileRepair {
+	return &reconcileRepair{fn: fn}
+}
+
+// apply runs the repair the first time it's called, returning whether it ran and the error of its one run
+func (r *reconcileRepair) apply(d *db.DirectoryDB) (bool, error) {
+	if r.applied {
+		return false, r.err
+	}
+	r.applied = true
+	r.err = r.fn(d)
+	return true, r.err
+}
+
+// reconcileWorker compares the directory with the arkeo api on a schedule, disabled when the interval is zero
+func (a *IndexerApp) reconcileWorker() {
+	if a.params.ReconcileInterval <= 0 {
+		log.Infof("reconciliation disabled")
+		return
+	}
+	log.Infof("starting reconcile worker, repair: %t", a.params.ReconcileRepair)
+	ticker := time.NewTicker(a.params.ReconcileInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := a.Reconcile(context.Background(), a.params.ReconcileRepair); err != nil {
+			log.Errorf("error reconciling with the arkeo api: %+v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// Reconcile compares providers and contracts in the directory with the chain's state from the arkeo api, records
+// the mismatches found and, when repair is set, corrects the directory where the chain's state is enough to do so
+func (a *IndexerApp) Reconcile(ctx context.Context, repair bool) (run *db.ReconcileRun, err error) {
+	defer func() { metrics.ObserveReconcile(err) }()
+
+	height, err := a.arkeo.LatestHeight(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error finding chain height")
+	}
+	entity, err := a.db.InsertReconcileRun(height, repair)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error inserting reconcile run")
+	}
+	run = &db.ReconcileRun{Entity: *entity, ChainHeight: height, Repair: repair}
+	diffs, err := a.reconcileDiffs(ctx, run)
+	if err == nil {
+		err = a.recordDiffs(run, diffs, repair)
+	}
+	if _, finishErr := a.db.FinishReconcileRun(run, err); finishErr != nil {
+		log.Errorf("error finishing reconcile run %d: %+v", run.ID, finishErr)
+	}
+	if err != nil {
+		return run, err
+	}
+	log.Infof("reconciled %d providers and %d contracts at height %d: %d mismatches, %d repaired",
+		run.ProvidersChecked, run.ContractsChecked, height, run.Mismatches, run.Repaired)
+	return run, nil
+}
+
+func (a *IndexerApp) reconcileDiffs(ctx context.Context, run *db.ReconcileRun) ([]*reconcileDiff, error) {
+	chainProviders, err := a.arkeo.Providers(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing chain providers")
+	}
+	chainContracts, err := a.arkeo.Contracts(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing chain contracts")
+	}
+	providers, err := a.db.FindAllProviders()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error finding providers")
+	}
+	contracts, err := a.db.FindReconcileContracts()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error finding contracts")
+	}
+	run.ProvidersChecked = int64(len(chainProviders))
+	run.ContractsChecked = int64(len(chainContracts))
+
+	diffs := compareProviders(providers, chainProviders)
+	return append(diffs, compareContracts(contracts, chainContracts, run.ChainHeight)...), nil
+}
+
+func (a *IndexerApp) recordDiffs(run *db.ReconcileRun, diffs []*reconcileDiff, repair bool) error {
+	metrics.ReconcileMismatches.Reset()
+	for _, diff := range diffs {
+		m := diff.mismatch
+		if repair && diff.repair != nil {
+			ran, err := diff.repair.apply(a.db)
+			switch {
+			case err != nil && ran:
+				log.Errorf("error repairing %s %s %s: %+v", m.EntityType, m.EntityKey, m.Field, err)
+			case err == nil:
+				m.Repaired = true
+				// counted once per repair rather than per mismatch it corrects
+				if ran {
+					run.Repaired++
+				}
+			}
+		}
+		m.RunID = run.ID
+		if _, err := a.db.InsertReconcileMismatch(m); err != nil {
+			return errors.Wrapf(err, "error inserting mismatch")
+		}
+		run.Mismatches++
+		metrics.ReconcileMismatches.WithLabelValues(m.EntityType, m.Field).Inc()
+	}
+	return nil
+}
+
+func providerKey(pubkey, chain string) string {
+	return pubkey + "/" + chain
+}
+
+// compareProviders finds providers whose bond, status, rates, durations or metadata differ from the chain's.
+// A provider with any mismatch is repaired by overwriting it with the chain's state
+func compareProviders(providers []*db.ArkeoProvider, chainProviders []*arkeo.Provider) []*reconcileDiff {
+	byKey := make(map[string]*db.ArkeoProvider, len(providers))
+	for _, p := range providers {
+		byKey[providerKey(p.Pubkey, p.Chain)] = p
+	}
+
+	diffs := make([]*reconcileDiff, 0)
+	for _, cp := range chainProviders {
+		key := providerKey(cp.PubKey, cp.Chain)
+		p, ok := byKey[key]
+		delete(byKey, key)
+		repair := newRepair(repairProvider(cp, !ok))
+		if !ok {
+			diffs = append(diffs, newDiff(reconcileProvider, key, "exists", reconcileMissing, reconcilePresent, repair))
+			continue
+		}
+		fields := []struct {
+			field, dir, chain string
+			same              bool
+		}{
+			{"bond", p.Bond.String(), cp.Bond.String(), p.Bond.Equal(cp.Bond)},
+			{"status", string(p.Status), cp.Status, sameEnum(string(p.Status), cp.Status)},
+			{"metadata_uri", p.MetadataURI, cp.MetadataURI, p.MetadataURI == cp.MetadataURI},
+			{"metadata_nonce", fmtInt(int64(p.MetadataNonce)), fmtInt(int64(cp.MetadataNonce)), p.MetadataNonce == uint64(cp.MetadataNonce)},
+			{"min_contract_duration", fmtInt(p.MinContractDuration), fmtInt(int64(cp.MinContractDuration)), p.MinContractDuration == int64(cp.MinContractDuration)},
+			{"max_contract_duration", fmtInt(p.MaxContractDuration), fmtInt(int64(cp.MaxContractDuration)), p.MaxContractDuration == int64(cp.MaxContractDuration)},
+			{"subscription_rate", p.SubscriptionRate.String(), cp.SubscriptionRate.String(), p.SubscriptionRate.Equal(cp.SubscriptionRate)},
+			{"paygo_rate", p.PayAsYouGoRate.String(), cp.PayAsYouGoRate.String(), p.PayAsYouGoRate.Equal(cp.PayAsYouGoRate)},
+		}
+		for _, f := range fields {
+			if !f.same {
+				diffs = append(diffs, newDiff(reconcileProvider, key, f.field, f.dir, f.chain, repair))
+			}
+		}
+	}
+	// providers that unbonded fully may be removed from the chain's store, so these are report only
+	for key := range byKey {
+		diffs = append(diffs, newDiff(reconcileProvider, key, "exists", reconcilePresent, reconcileMissing, nil))
+	}
+	return diffs
+}
+
+// compareContracts finds contracts whose rate, type, end height or settled nonce differ from the chain's. A
+// contract closed early ends at its closed height in the directory, the chain shortens its duration instead
+func compareContracts(contracts []*db.ReconcileContract, chainContracts []*arkeo.Contract, height int64) []*reconcileDiff {
+	byKey := make(map[string]*db.ReconcileContract, len(contracts))
+	for _, c := range contracts {
+		byKey[contractKey(c.ProviderPubkey, c.Chain, c.DelegatePubkey, c.Height)] = c
+	}
+
+	diffs := make([]*reconcileDiff, 0)
+	for _, cc := range chainContracts {
+		key := contractKey(cc.ProviderPubKey, cc.Chain, cc.DelegatePubkey(), int64(cc.Height))
+		c, ok := byKey[key]
+		delete(byKey, key)
+		if !ok {
+			// creating the contract needs its open event, leave it to reindexing
+			diffs = append(diffs, newDiff(reconcileContract, key, "exists", reconcileMissing, reconcilePresent, nil))
+			continue
+		}
+		if !c.Rate.Equal(cc.Rate) {
+			diffs = append(diffs, newDiff(reconcileContract, key, "rate", c.Rate.String(), cc.Rate.String(), nil))
+		}
+		if !sameEnum(string(c.ContractType), cc.Type) {
+			diffs = append(diffs, newDiff(reconcileContract, key, "contract_type", string(c.ContractType), cc.Type, nil))
+		}
+		if end, chainEnd := contractEnd(&c.ArkeoContract), int64(cc.Height)+int64(cc.Duration); end != chainEnd {
+			diffs = append(diffs, newDiff(reconcileContract, key, "end_height", fmtInt(end), fmtInt(chainEnd),
+				newRepair(repairContractEnd(&c.ArkeoContract, chainEnd))))
+		}
+		if cc.ID > 0 && c.ContractID != uint64(cc.ID) {
+			var repair *reconcileRepair
+			if c.ContractID == 0 {
+				repair = newRepair(repairContractID(&c.ArkeoContract, uint64(cc.ID)))
+			}
+			diffs = append(diffs, newDiff(reconcileContract, key, "contract_id", strconv.FormatUint(c.ContractID, 10),
+				fmtInt(int64(cc.ID)), repair))
+		}
+		if c.SettlementNonce != int64(cc.Nonce) {
+			diffs = append(diffs, newDiff(reconcileContract, key, "settlement_nonce", fmtInt(c.SettlementNonce),
+				fmtInt(int64(cc.Nonce)), nil))
+		}
+	}
+	// the chain drops contracts once they expire and settle, only those the directory still has open are drift
+	for key, c := range byKey {
+		if contractEnd(&c.ArkeoContract) > height {
+			diffs = append(diffs, newDiff(reconcileContract, key, "exists", reconcilePresent, reconcileMissing, nil))
+		}
+	}
+	return diffs
+}
+
+func contractKey(providerPubkey, chain, delegatePubkey string, height int64) string {
+	return fmt.Sprintf("%s/%s/%s/%d", providerPubkey, chain, delegatePubkey, height)
+}
+
+// height the contract ends at, its closed height when closed early
+func contractEnd(c *db.ArkeoContract) int64 {
+	if c.ClosedHeight > 0 {
+		return c.ClosedHeight
+	}
+	return c.Height + c.Duration
+}
+
+func repairProvider(cp *arkeo.Provider, insert bool) func(d *db.DirectoryDB) error {
+	return func(d *db.DirectoryDB) error {
+		p := &db.ArkeoProvider{
+			Pubkey:              cp.PubKey,
+			Chain:               cp.Chain,
+			Bond:                cp.Bond,
+			MetadataURI:         cp.MetadataURI,
+			MetadataNonce:       uint64(cp.MetadataNonce),
+			Status:              providerStatus(cp.Status),
+			MinContractDuration: int64(cp.MinContractDuration),
+			MaxContractDuration: int64(cp.MaxContractDuration),
+			SubscriptionRate:    cp.SubscriptionRate,
+			PayAsYouGoRate:      cp.PayAsYouGoRate,
+		}
+		if insert {
+			if _, err := d.InsertProvider(p); err != nil {
+				return errors.Wrapf(err, "error inserting provider")
+			}
+		}
+		_, err := d.UpdateProvider(p)
+		return err
+	}
+}
+
+// close the contract at the chain's end height, or reopen it when the chain hasn't shortened its duration
+func repairContractEnd(c *db.ArkeoContract, chainEnd int64) func(d *db.DirectoryDB) error {
+	closedHeight := chainEnd
+	if chainEnd == c.Height+c.Duration {
+		closedHeight = 0
+	}
+	return func(d *db.DirectoryDB) error {
+		_, err := d.CloseContract(c.ID, closedHeight)
+		return err
+	}
+}
+
+// contracts indexed before events carried the chain's id are given it, differing ids are left for reindexing
+func repairContractID(c *db.ArkeoContract, contractID uint64) func(d *db.DirectoryDB) error {
+	return func(d *db.DirectoryDB) error {
+		_, err := d.SetContractID(c.ID, contractID)
+		return err
+	}
+}
+
+func newDiff(entityType, key, field, dir, chain string, repair *reconcileRepair) *reconcileDiff {
+	return &reconcileDiff{
+		mismatch: &db.ReconcileMismatch{
+			EntityType:     entityType,
+			EntityKey:      key,
+			Field:          field,
+			DirectoryValue: dir,
+			ChainValue:     chain,
+		},
+		repair: repair,
+	}
+}
+
+// the api spells enums in upper snake case, the directory in camel case
+func sameEnum(dir, chain string) bool {
+	return normalizeEnum(dir) == normalizeEnum(chain)
+}
+
+func normalizeEnum(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+func providerStatus(s string) types.ProviderStatus {
+	if normalizeEnum(s) == normalizeEnum(string(types.ProviderStatusOnline)) {
+		return types.ProviderStatusOnline
+	}
+	return types.ProviderStatusOffline
+}
+
+func fmtInt(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
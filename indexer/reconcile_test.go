@@ -0,0 +1,126 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/arkeonetwork/directory/pkg/arkeo"
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/types"
+)
+
+func amt(i int64) types.Amount {
+	return types.NewAmount(i, types.DefaultDenom)
+}
+
+func mismatchFields(diffs []*reconcileDiff) map[string]*reconcileDiff {
+	fields := make(map[string]*reconcileDiff, len(diffs))
+	for _, d := range diffs {
+		fields[d.mismatch.EntityKey+" "+d.mismatch.Field] = d
+	}
+	return fields
+}
+
+func TestCompareProviders(t *testing.T) {
+	providers := []*db.ArkeoProvider{
+		{Pubkey: "pk1", Chain: "btc", Bond: amt(100), Status: types.ProviderStatusOnline, SubscriptionRate: amt(10)},
+		{Pubkey: "pk2", Chain: "btc", Bond: amt(5), Status: types.ProviderStatusOnline, PayAsYouGoRate: amt(1)},
+		{Pubkey: "gone", Chain: "btc", Bond: amt(0), Status: types.ProviderStatusOffline},
+	}
+	chain := []*arkeo.Provider{
+		{PubKey: "pk1", Chain: "btc", Bond: amt(100), Status: "ONLINE", SubscriptionRate: amt(10)},
+		{PubKey: "pk2", Chain: "btc", Bond: amt(7), Status: "OFFLINE", PayAsYouGoRate: amt(1)},
+		{PubKey: "new", Chain: "btc", Bond: amt(1), Status: "ONLINE"},
+	}
+	fields := mismatchFields(compareProviders(providers, chain))
+	for _, key := range []string{"pk2/btc bond", "pk2/btc status", "new/btc exists", "gone/btc exists"} {
+		if fields[key] == nil {
+			t.Errorf("expected mismatch %s", key)
+		}
+	}
+	if len(fields) != 4 {
+		t.Errorf("expected 4 mismatches got %d: %v", len(fields), fields)
+	}
+	if d := fields["pk2/btc bond"]; d != nil && (d.mismatch.DirectoryValue != "5uarkeo" || d.mismatch.ChainValue != "7uarkeo" || d.repair == nil) {
+		t.Errorf("unexpected bond mismatch %+v", d.mismatch)
+	}
+	if d := fields["gone/btc exists"]; d != nil && d.repair != nil {
+		t.Errorf("expected providers missing from the chain to be report only")
+	}
+
+	// the mismatches of a provider are repaired by one upsert
+	bond, status := fields["pk2/btc bond"], fields["pk2/btc status"]
+	if bond == nil || status == nil || bond.repair != status.repair {
+		t.Fatalf("expected the provider's mismatches to share a repair")
+	}
+	runs := 0
+	bond.repair.fn = func(d *db.DirectoryDB) error { runs++; return nil }
+	for _, d := range []*reconcileDiff{bond, status} {
+		if _, err := d.repair.apply(nil); err != nil {
+			t.Errorf("unexpected repair error %+v", err)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("expected the repair to run once got %d", runs)
+	}
+}
+
+func TestCompareContracts(t *testing.T) {
+	contract := func(id int64, delegate string, height, duration, closed, nonce int64) *db.ReconcileContract {
+		return &db.ReconcileContract{
+			ArkeoContract: db.ArkeoContract{
+				Entity:         db.Entity{ID: id},
+				DelegatePubkey: delegate,
+				Height:         height,
+				Duration:       duration,
+				ClosedHeight:   closed,
+				Rate:           amt(10),
+				ContractType:   types.ContractTypeSubscription,
+			},
+			ProviderPubkey:  "pk1",
+			Chain:           "btc",
+			SettlementNonce: nonce,
+		}
+	}
+	contracts := []*db.ReconcileContract{
+		contract(1, "ok", 100, 50, 0, 2),
+		// closed early on chain, open in the directory
+		contract(2, "unclosed", 100, 50, 0, 0),
+		// closed in the directory, chain still has the full duration
+		contract(3, "closed", 100, 50, 120, 0),
+		contract(4, "settled", 100, 50, 0, 1),
+		// open in the directory but the chain no longer has it
+		contract(5, "dropped", 900, 500, 0, 0),
+		// expired and pruned on chain, not drift
+		contract(6, "expired", 10, 10, 0, 0),
+	}
+	chain := []*arkeo.Contract{
+		{ProviderPubKey: "pk1", Chain: "btc", Client: "ok", Type: "SUBSCRIPTION", Height: 100, Duration: 50, Rate: amt(10), Nonce: 2},
+		{ProviderPubKey: "pk1", Chain: "btc", Client: "unclosed", Type: "SUBSCRIPTION", Height: 100, Duration: 20, Rate: amt(10)},
+		{ProviderPubKey: "pk1", Chain: "btc", Client: "closed", Type: "SUBSCRIPTION", Height: 100, Duration: 50, Rate: amt(10)},
+		{ProviderPubKey: "pk1", Chain: "btc", Client: "settled", Type: "SUBSCRIPTION", Height: 100, Duration: 50, Rate: amt(10), Nonce: 3, ID: 7},
+		{ProviderPubKey: "pk1", Chain: "btc", Client: "c", Delegate: "unknown", Type: "PAY_AS_YOU_GO", Height: 130, Duration: 50, Rate: amt(1)},
+	}
+	fields := mismatchFields(compareContracts(contracts, chain, 1000))
+	expected := []string{
+		"pk1/btc/unclosed/100 end_height",
+		"pk1/btc/closed/100 end_height",
+		"pk1/btc/settled/100 settlement_nonce",
+		"pk1/btc/settled/100 contract_id",
+		"pk1/btc/dropped/900 exists",
+		"pk1/btc/unknown/130 exists",
+	}
+	for _, key := range expected {
+		if fields[key] == nil {
+			t.Errorf("expected mismatch %s", key)
+		}
+	}
+	if len(fields) != len(expected) {
+		t.Errorf("expected %d mismatches got %d", len(expected), len(fields))
+	}
+	if d := fields["pk1/btc/unclosed/100 end_height"]; d != nil && (d.mismatch.DirectoryValue != "150" || d.mismatch.ChainValue != "120" || d.repair == nil) {
+		t.Errorf("unexpected end height mismatch %+v", d.mismatch)
+	}
+	if d := fields["pk1/btc/settled/100 settlement_nonce"]; d != nil && d.repair != nil {
+		t.Errorf("expected settlement nonce mismatches to be report only")
+	}
+}
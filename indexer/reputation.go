@@ -0,0 +1,109 @@
+package indexer
+
+import (
+	"math"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/pkg/errors"
+)
+
+const (
+	reputationInterval = 10 * time.Minute
+	// blocks of age at which the age component is maxed, roughly a month at 5s blocks
+	reputationAgeTarget = 500_000
+)
+
+// weights of the reputation components, renormalized when metadata validity is unknown
+var reputationWeights = struct {
+	age, bond, contracts, settlement, validator, metadata float64
+}{
+	age:        0.15,
+	bond:       0.20,
+	contracts:  0.25,
+	settlement: 0.20,
+	validator:  0.10,
+	metadata:   0.10,
+}
+
+// reputationWorker recomputes every provider's reputation score on a schedule
+func (a *IndexerApp) reputationWorker() {
+	log.Infof("starting reputation worker")
+	ticker := time.NewTicker(reputationInterval)
+	defer ticker.Stop()
+	for {
+		if err := a.updateReputations(); err != nil {
+			log.Errorf("error updating reputations: %+v", err)
+		}
+		<-ticker.C
+	}
+}
+
+func (a *IndexerApp) updateReputations() error {
+	inputs, err := a.db.FindReputationInputs()
+	if err != nil {
+		return errors.Wrapf(err, "error finding reputation inputs")
+	}
+	for _, r := range scoreReputations(inputs) {
+		if _, err = a.db.UpsertProviderReputation(*r); err != nil {
+			log.Errorf("error upserting reputation for provider %d: %+v", r.ProviderID, err)
+		}
+	}
+	log.Debugf("updated reputation of %d providers", len(inputs))
+	return nil
+}
+
+// score providers relative to each other. bond, settlement volume and validator payouts are log scaled against the
+// largest value among all providers, age against reputationAgeTarget, and contract history is the share of contracts
+// completed rather than closed early with one of each assumed so new providers start in the middle
+func scoreReputations(inputs []*db.ReputationInputs) []*db.ProviderReputation {
+	var maxBond, maxSettlement, maxValidator float64
+	for _, in := range inputs {
+		maxBond = math.Max(maxBond, in.Bond)
+		maxSettlement = math.Max(maxSettlement, in.SettlementVolume)
+		maxValidator = math.Max(maxValidator, in.ValidatorPaid)
+	}
+
+	w := reputationWeights
+	results := make([]*db.ProviderReputation, 0, len(inputs))
+	for _, in := range inputs {
+		r := &db.ProviderReputation{
+			ProviderID:           in.ProviderID,
+			AgeScore:             math.Min(float64(in.Age)/reputationAgeTarget, 1),
+			BondScore:            logScale(in.Bond, maxBond),
+			ContractsScore:       float64(in.CompletedContracts+1) / float64(in.CompletedContracts+in.EarlyClosedContracts+2),
+			SettlementScore:      logScale(in.SettlementVolume, maxSettlement),
+			ValidatorScore:       logScale(in.ValidatorPaid, maxValidator),
+			CompletedContracts:   in.CompletedContracts,
+			EarlyClosedContracts: in.EarlyClosedContracts,
+			ComputedHeight:       in.CurHeight,
+		}
+		if r.AgeScore < 0 {
+			r.AgeScore = 0
+		}
+
+		total := w.age*r.AgeScore + w.bond*r.BondScore + w.contracts*r.ContractsScore +
+			w.settlement*r.SettlementScore + w.validator*r.ValidatorScore
+		weights := w.age + w.bond + w.contracts + w.settlement + w.validator
+		if in.MetadataValid != nil {
+			metadataScore := 0.0
+			if *in.MetadataValid {
+				metadataScore = 1
+			}
+			r.MetadataScore = &metadataScore
+			total += w.metadata * metadataScore
+			weights += w.metadata
+		}
+		r.Score = math.Round(total/weights*10000) / 100
+		results = append(results, r)
+	}
+	return results
+}
+
+// log1p(v) relative to log1p(max), 0 when there is nothing to compare against
+func logScale(v, max float64) float64 {
+	if v <= 0 || max <= 0 {
+		return 0
+	}
+	return math.Log1p(v) / math.Log1p(max)
+}
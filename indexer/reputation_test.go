@@ -0,0 +1,50 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+)
+
+func TestScoreReputations(t *testing.T) {
+	valid, invalid := true, false
+	inputs := []*db.ReputationInputs{
+		// established, busy provider
+		{ProviderID: 1, Age: reputationAgeTarget * 2, Bond: 1e9, SettlementVolume: 1e8, ValidatorPaid: 5000, CompletedContracts: 40, EarlyClosedContracts: 2, MetadataValid: &valid},
+		// brand new provider, no metadata yet
+		{ProviderID: 2, Age: 10, Bond: 1e6},
+		// closes most contracts early, invalid metadata
+		{ProviderID: 3, Age: reputationAgeTarget / 2, Bond: 1e9, SettlementVolume: 1e6, CompletedContracts: 1, EarlyClosedContracts: 9, MetadataValid: &invalid},
+	}
+	results := scoreReputations(inputs)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results got %d", len(results))
+	}
+	byID := map[int64]*db.ProviderReputation{}
+	for _, r := range results {
+		if r.Score < 0 || r.Score > 100 {
+			t.Errorf("score %f out of range for provider %d", r.Score, r.ProviderID)
+		}
+		byID[r.ProviderID] = r
+	}
+
+	best := byID[1]
+	if best.AgeScore != 1 || best.BondScore != 1 || best.SettlementScore != 1 || best.ValidatorScore != 1 {
+		t.Errorf("expected maxed components got %+v", best)
+	}
+	if best.MetadataScore == nil || *best.MetadataScore != 1 {
+		t.Errorf("expected metadata score 1 got %v", best.MetadataScore)
+	}
+	if byID[2].MetadataScore != nil {
+		t.Errorf("expected no metadata score got %v", *byID[2].MetadataScore)
+	}
+	if byID[2].ContractsScore != 0.5 {
+		t.Errorf("expected neutral contract score for new provider got %f", byID[2].ContractsScore)
+	}
+	if !(best.Score > byID[3].Score && best.Score > byID[2].Score) {
+		t.Errorf("expected provider 1 to rank first: %f %f %f", best.Score, byID[2].Score, byID[3].Score)
+	}
+	if byID[3].ContractsScore >= byID[2].ContractsScore {
+		t.Errorf("expected early closes to lower the contract score: %f", byID[3].ContractsScore)
+	}
+}
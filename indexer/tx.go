@@ -0,0 +1,359 @@
+package indexer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+	"github.com/arkeonetwork/directory/pkg/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authztypes "github.com/cosmos/cosmos-sdk/x/authz"
+	"github.com/pkg/errors"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	msgExecTypeURL     = "/cosmos.authz.v1beta1.MsgExec"
+	arkeoMsgTypePrefix = "/arkeo.arkeo."
+	// attribute authz adds to the events of a message a MsgExec dispatches
+	authzMsgIndexKey = "authz_msg_index"
+)
+
+// the arkeo message emitting each type of event handled from txs, settlement events are emitted by the close or claim
+// message preceding them
+var arkeoEventMsgs = map[string]string{
+	"provider_bond":         "/arkeo.arkeo.MsgBondProvider",
+	"provider_mod":          "/arkeo.arkeo.MsgModProvider",
+	"open_contract":         "/arkeo.arkeo.MsgOpenContract",
+	"close_contract":        "/arkeo.arkeo.MsgCloseContract",
+	"claim_contract_income": "/arkeo.arkeo.MsgClaimContractIncome",
+}
+
+// a message of a tx and the account that signed it. Grantee is set for messages executed through authz MsgExec
+type txMessage struct {
+	typeURL string
+	signer  string
+	grantee string
+	value   []byte
+}
+
+// the parts of a tx events are attributed to. each top-level message holds the messages whose events are emitted
+// under its index, itself or the messages a MsgExec wraps
+type decodedTx struct {
+	memo     string
+	feePayer string
+	// the signer of the first message, the grantee of a MsgExec
+	signer string
+	fee    sdk.Coins
+	msgs   [][]*txMessage
+	// per top-level message, the position among its messages of the last one an event was attributed to and where
+	// matching the next event by type starts
+	last []int
+	next []int
+}
+
+// decodeTx decodes a tx's messages, signers, fee payer and memo. arkeo's message types aren't registered with the
+// encoding, so messages are decoded without resolving them and an arkeo message's signer is read from its leading
+// creator field
+func (a *IndexerApp) decodeTx(raw tmtypes.Tx) (*decodedTx, error) {
+	var txRaw txtypes.TxRaw
+	if err := a.encoding.Marshaler.Unmarshal(raw, &txRaw); err != nil {
+		return nil, errors.Wrapf(err, "error decoding tx")
+	}
+	var body txtypes.TxBody
+	if err := body.Unmarshal(txRaw.BodyBytes); err != nil {
+		return nil, errors.Wrapf(err, "error decoding tx body")
+	}
+	var authInfo txtypes.AuthInfo
+	if err := a.encoding.Marshaler.Unmarshal(txRaw.AuthInfoBytes, &authInfo); err != nil {
+		return nil, errors.Wrapf(err, "error decoding tx auth info")
+	}
+
+	// signer infos only carry the pubkeys of accounts new to the chain, the first is the tx's signer when present
+	txSigner := ""
+	if len(authInfo.SignerInfos) > 0 && authInfo.SignerInfos[0].PublicKey != nil {
+		if pk, ok := authInfo.SignerInfos[0].PublicKey.GetCachedValue().(cryptotypes.PubKey); ok {
+			addr, err := bech32.ConvertAndEncode(a.params.Bech32PrefixAccAddr, pk.Address())
+			if err != nil {
+				return nil, errors.Wrapf(err, "error encoding signer address")
+			}
+			txSigner = addr
+		}
+	}
+
+	tx := &decodedTx{memo: body.Memo}
+	for i, msg := range body.Messages {
+		if msg.TypeUrl != msgExecTypeURL {
+			tx.msgs = append(tx.msgs, []*txMessage{{typeURL: msg.TypeUrl, signer: a.msgSigner(msg.Value, txSigner), value: msg.Value}})
+			continue
+		}
+		var exec authztypes.MsgExec
+		if err := exec.Unmarshal(msg.Value); err != nil {
+			return nil, errors.Wrapf(err, "error decoding MsgExec %d", i)
+		}
+		inner := make([]*txMessage, 0, len(exec.Msgs))
+		for _, m := range exec.Msgs {
+			inner = append(inner, &txMessage{typeURL: m.TypeUrl, signer: a.msgSigner(m.Value, ""), grantee: exec.Grantee, value: m.Value})
+		}
+		if len(inner) == 0 {
+			inner = append(inner, &txMessage{typeURL: msg.TypeUrl, signer: exec.Grantee})
+		}
+		tx.msgs = append(tx.msgs, inner)
+	}
+	tx.last = make([]int, len(tx.msgs))
+	tx.next = make([]int, len(tx.msgs))
+
+	if len(tx.msgs) > 0 {
+		first := tx.msgs[0][0]
+		tx.signer = first.signer
+		if first.grantee != "" {
+			tx.signer = first.grantee
+		}
+	}
+	if tx.signer == "" {
+		tx.signer = txSigner
+	}
+	// the fee is paid by the first signer unless a payer is set
+	tx.feePayer = tx.signer
+	if authInfo.Fee != nil {
+		tx.fee = authInfo.Fee.Amount
+		if authInfo.Fee.Payer != "" {
+			tx.feePayer = authInfo.Fee.Payer
+		}
+	}
+	return tx, nil
+}
+
+// isArkeoEvent reports whether a tx event is one the indexer handles
+func isArkeoEvent(eventType string) bool {
+	_, ok := arkeoEventMsgs[eventType]
+	return ok || eventType == "contract_settlement"
+}
+
+// txRecord is the stored form of a tx, tx is nil when it could not be decoded
+func txRecord(hash string, height int64, tx *decodedTx, result abcitypes.ResponseDeliverTx) db.Tx {
+	record := db.Tx{
+		TxID:      hash,
+		Height:    height,
+		Fee:       types.NewAmount(0, types.DefaultDenom),
+		GasWanted: result.GasWanted,
+		GasUsed:   result.GasUsed,
+		Code:      result.Code,
+		Success:   result.Code == 0,
+	}
+	if tx != nil {
+		record.Signer = tx.signer
+		record.FeePayer = tx.feePayer
+		record.Memo = tx.memo
+		record.Fee = types.NewAmountFromBigInt(tx.fee.AmountOf(types.DefaultDenom).BigInt(), types.DefaultDenom)
+		record.FeeCoins = tx.fee.String()
+	}
+	return record
+}
+
+// failedTxRecords are the stored forms of the arkeo messages of a failed tx, including those a MsgExec wraps, keyed
+// by the top-level message index events carry and the position within the MsgExec. none are returned for a tx
+// without arkeo messages
+func (a *IndexerApp) failedTxRecords(hash string, height int64, tx *decodedTx, result abcitypes.ResponseDeliverTx) []db.FailedTx {
+	records := make([]db.FailedTx, 0, 1)
+	if tx == nil {
+		return records
+	}
+	for msgIndex, msgs := range tx.msgs {
+		for execIndex, msg := range msgs {
+			if !strings.HasPrefix(msg.typeURL, arkeoMsgTypePrefix) {
+				continue
+			}
+			fields, err := msgFields(msg.value)
+			if err != nil {
+				log.Warnf("recording undecodable %s of failed tx %s: %+v", msg.typeURL, hash, err)
+				fields = map[string]interface{}{}
+			}
+			records = append(records, db.FailedTx{
+				TxID:      hash,
+				MsgIndex:  msgIndex,
+				ExecIndex: execIndex,
+				Height:    height,
+				MsgType:   msg.typeURL,
+				Msg:       fields,
+				Provider:  a.msgProvider(fields),
+				Signer:    msg.signer,
+				Grantee:   msg.grantee,
+				FeePayer:  tx.feePayer,
+				Memo:      tx.memo,
+				Code:      result.Code,
+				Codespace: result.Codespace,
+				Log:       result.Log,
+			})
+		}
+	}
+	return records
+}
+
+// msgProvider reads the provider pubkey arkeo's provider and open contract messages carry as field 2
+func (a *IndexerApp) msgProvider(fields map[string]interface{}) string {
+	provider, ok := fields["2"].(string)
+	if !ok {
+		return ""
+	}
+	if hrp, _, err := bech32.DecodeAndConvert(provider); err != nil || hrp != a.params.Bech32PrefixAccPub {
+		return ""
+	}
+	return provider
+}
+
+// msgFields decodes a protobuf message without its type by field number. varints are kept as numbers, length
+// delimited fields as text when printable, else as an embedded message when they parse as one, else base64.
+// repeated fields become lists
+func msgFields(msg []byte) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		msg = msg[n:]
+		var value interface{}
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			value, msg = v, msg[n:]
+		case protowire.Fixed32Type:
+			v, n := protowire.ConsumeFixed32(msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			value, msg = v, msg[n:]
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			value, msg = v, msg[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			value, msg = bytesField(v), msg[n:]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d of field %d", typ, num)
+		}
+		key := strconv.Itoa(int(num))
+		switch prev := fields[key].(type) {
+		case nil:
+			fields[key] = value
+		case []interface{}:
+			fields[key] = append(prev, value)
+		default:
+			fields[key] = []interface{}{prev, value}
+		}
+	}
+	return fields, nil
+}
+
+func bytesField(v []byte) interface{} {
+	if printable(v) {
+		return string(v)
+	}
+	if nested, err := msgFields(v); err == nil && len(nested) > 0 {
+		return nested
+	}
+	return base64.StdEncoding.EncodeToString(v)
+}
+
+func printable(v []byte) bool {
+	if !utf8.Valid(v) {
+		return false
+	}
+	for _, r := range string(v) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// msgSigner reads the creator, the first field of arkeo's messages, falling back to fallback when it isn't an
+// account. the creator is either a bech32 address or, cast to AccAddress, its raw bytes
+func (a *IndexerApp) msgSigner(msg []byte, fallback string) string {
+	creator, err := leadingBytes(msg)
+	if err != nil {
+		return fallback
+	}
+	if len(creator) == 20 {
+		addr, err := bech32.ConvertAndEncode(a.params.Bech32PrefixAccAddr, creator)
+		if err != nil {
+			return fallback
+		}
+		return addr
+	}
+	if hrp, _, err := bech32.DecodeAndConvert(string(creator)); err != nil || hrp != a.params.Bech32PrefixAccAddr {
+		return fallback
+	}
+	return string(creator)
+}
+
+// leadingBytes decodes field 1 of a protobuf message as bytes
+func leadingBytes(msg []byte) ([]byte, error) {
+	num, typ, n := protowire.ConsumeTag(msg)
+	if n < 0 {
+		return nil, protowire.ParseError(n)
+	}
+	if num != 1 || typ != protowire.BytesType {
+		return nil, fmt.Errorf("field 1 is not first")
+	}
+	value, n := protowire.ConsumeBytes(msg[n:])
+	if n < 0 {
+		return nil, protowire.ParseError(n)
+	}
+	return value, nil
+}
+
+// signerOf attributes an event emitted under a top-level message to the message that emitted it. authz tags the
+// events of each message a MsgExec dispatches with its position, events without one are matched in order against
+// the MsgExec's messages by the type of message emitting them and other events attributed to the last message matched
+func (tx *decodedTx) signerOf(msgIndex int, event abcitypes.Event) types.TxSigner {
+	if tx == nil || msgIndex >= len(tx.msgs) {
+		return types.TxSigner{}
+	}
+	msgs := tx.msgs[msgIndex]
+	if i, ok := authzMsgIndex(event); ok && i < len(msgs) {
+		tx.last[msgIndex] = i
+		tx.next[msgIndex] = i + 1
+	} else if typeURL, ok := arkeoEventMsgs[event.Type]; ok {
+		for i := tx.next[msgIndex]; i < len(msgs); i++ {
+			if msgs[i].typeURL == typeURL {
+				tx.last[msgIndex] = i
+				tx.next[msgIndex] = i + 1
+				break
+			}
+		}
+	}
+	msg := msgs[tx.last[msgIndex]]
+	return types.TxSigner{Signer: msg.signer, Grantee: msg.grantee, FeePayer: tx.feePayer, Memo: tx.memo}
+}
+
+// authzMsgIndex reads the position among a MsgExec's messages authz tags the message's events with
+func authzMsgIndex(event abcitypes.Event) (int, bool) {
+	for _, attr := range event.Attributes {
+		if string(attr.Key) != authzMsgIndexKey {
+			continue
+		}
+		i, err := strconv.Atoi(string(attr.Value))
+		return i, err == nil && i >= 0
+	}
+	return 0, false
+}
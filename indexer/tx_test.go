@@ -0,0 +1,241 @@
+package indexer
+
+import (
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authztypes "github.com/cosmos/cosmos-sdk/x/authz"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestDecodeTx(t *testing.T) {
+	a := &IndexerApp{params: IndexerAppParams{Bech32PrefixAccAddr: "tarkeo"}, encoding: NewEncoding()}
+	address := func(b byte) string {
+		addr, err := bech32.ConvertAndEncode("tarkeo", []byte{b, b, b, b, b, b, b, b, b, b, b, b, b, b, b, b, b, b, b, b})
+		if err != nil {
+			t.Fatalf("error encoding address: %+v", err)
+		}
+		return addr
+	}
+	// arkeo messages lead with their creator
+	arkeoMsg := func(typeURL, creator string) *codectypes.Any {
+		bz := protowire.AppendTag(nil, 1, protowire.BytesType)
+		bz = protowire.AppendString(bz, creator)
+		bz = protowire.AppendTag(bz, 2, protowire.BytesType)
+		bz = protowire.AppendString(bz, "tarkeopub1provider")
+		return &codectypes.Any{TypeUrl: typeURL, Value: bz}
+	}
+	granter, grantee, direct := address(1), address(2), address(3)
+
+	execMsg := func(msgs ...*codectypes.Any) *codectypes.Any {
+		bz, err := (&authztypes.MsgExec{Grantee: grantee, Msgs: msgs}).Marshal()
+		if err != nil {
+			t.Fatalf("error encoding exec: %+v", err)
+		}
+		return &codectypes.Any{TypeUrl: msgExecTypeURL, Value: bz}
+	}
+	pk, err := codectypes.NewAnyWithValue(secp256k1.GenPrivKey().PubKey())
+	if err != nil {
+		t.Fatalf("error packing pubkey: %+v", err)
+	}
+	encodeTx := func(msgs ...*codectypes.Any) []byte {
+		bodyBz, err := (&txtypes.TxBody{Memo: "batch", Messages: msgs}).Marshal()
+		if err != nil {
+			t.Fatalf("error encoding body: %+v", err)
+		}
+		authInfo := txtypes.AuthInfo{SignerInfos: []*txtypes.SignerInfo{{PublicKey: pk}}, Fee: &txtypes.Fee{}}
+		authInfoBz, err := authInfo.Marshal()
+		if err != nil {
+			t.Fatalf("error encoding auth info: %+v", err)
+		}
+		raw, err := (&txtypes.TxRaw{BodyBytes: bodyBz, AuthInfoBytes: authInfoBz, Signatures: [][]byte{{1}}}).Marshal()
+		if err != nil {
+			t.Fatalf("error encoding tx: %+v", err)
+		}
+		return raw
+	}
+	event := func(typ string, attrs ...string) abcitypes.Event {
+		evt := abcitypes.Event{Type: typ}
+		for i := 0; i+1 < len(attrs); i += 2 {
+			evt.Attributes = append(evt.Attributes, abcitypes.EventAttribute{Key: []byte(attrs[i]), Value: []byte(attrs[i+1])})
+		}
+		return evt
+	}
+
+	raw := encodeTx(
+		arkeoMsg("/arkeo.arkeo.MsgBondProvider", direct),
+		execMsg(arkeoMsg("/arkeo.arkeo.MsgOpenContract", granter), arkeoMsg("/arkeo.arkeo.MsgOpenContract", direct)),
+	)
+	tx, err := a.decodeTx(raw)
+	if err != nil {
+		t.Fatalf("error decoding tx: %+v", err)
+	}
+	if tx.memo != "batch" || tx.feePayer != direct {
+		t.Errorf("unexpected memo %s or fee payer %s", tx.memo, tx.feePayer)
+	}
+	if s := tx.signerOf(0, event("provider_bond")); s.Signer != direct || s.Grantee != "" || s.Memo != "batch" {
+		t.Errorf("unexpected bond signer %+v", s)
+	}
+	// the exec's open contract events are matched in order to its messages
+	if s := tx.signerOf(1, event("open_contract")); s.Signer != granter || s.Grantee != grantee {
+		t.Errorf("unexpected first open signer %+v", s)
+	}
+	if s := tx.signerOf(1, event("open_contract")); s.Signer != direct || s.Grantee != grantee {
+		t.Errorf("unexpected second open signer %+v", s)
+	}
+	if s := tx.signerOf(1, event("coin_spent")); s.Signer != direct {
+		t.Errorf("unexpected signer of unmatched event %+v", s)
+	}
+	if s := tx.signerOf(5, event("open_contract")); s.Signer != "" {
+		t.Errorf("expected no signer beyond the tx's messages, got %+v", s)
+	}
+
+	// settlements of an exec's claims are attributed by the message index authz tags them with, not the last claim
+	claims, err := a.decodeTx(encodeTx(execMsg(
+		arkeoMsg("/arkeo.arkeo.MsgClaimContractIncome", granter),
+		arkeoMsg("/arkeo.arkeo.MsgClaimContractIncome", direct),
+	)))
+	if err != nil {
+		t.Fatalf("error decoding claims tx: %+v", err)
+	}
+	if s := claims.signerOf(0, event("claim_contract_income", "authz_msg_index", "0")); s.Signer != granter {
+		t.Errorf("unexpected first claim signer %+v", s)
+	}
+	if s := claims.signerOf(0, event("claim_contract_income", "authz_msg_index", "1")); s.Signer != direct {
+		t.Errorf("unexpected second claim signer %+v", s)
+	}
+	if s := claims.signerOf(0, event("contract_settlement", "authz_msg_index", "0")); s.Signer != granter || s.Grantee != grantee {
+		t.Errorf("unexpected first settlement signer %+v", s)
+	}
+	if s := claims.signerOf(0, event("contract_settlement")); s.Signer != granter {
+		t.Errorf("unexpected signer of untagged settlement %+v", s)
+	}
+
+	var nilTx *decodedTx
+	if s := nilTx.signerOf(0, event("open_contract")); s.Signer != "" {
+		t.Errorf("expected no signer of an undecoded tx, got %+v", s)
+	}
+	if _, err = a.decodeTx([]byte("not a tx")); err == nil {
+		t.Errorf("expected error decoding garbage")
+	}
+}
+
+func TestMsgSigner(t *testing.T) {
+	a := &IndexerApp{params: IndexerAppParams{Bech32PrefixAccAddr: "tarkeo"}}
+	raw := []byte{7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7}
+	addr, err := bech32.ConvertAndEncode("tarkeo", raw)
+	if err != nil {
+		t.Fatalf("error encoding address: %+v", err)
+	}
+	creator := func(value []byte) []byte {
+		bz := protowire.AppendTag(nil, 1, protowire.BytesType)
+		return protowire.AppendBytes(bz, value)
+	}
+	// a creator cast to AccAddress is its raw bytes
+	if signer := a.msgSigner(creator(raw), "fallback"); signer != addr {
+		t.Errorf("expected signer %s from raw bytes got %s", addr, signer)
+	}
+	if signer := a.msgSigner(creator([]byte(addr)), "fallback"); signer != addr {
+		t.Errorf("expected signer %s from bech32 got %s", addr, signer)
+	}
+	if signer := a.msgSigner(creator([]byte("cosmos1notarkeo")), "fallback"); signer != "fallback" {
+		t.Errorf("expected fallback for a non account creator got %s", signer)
+	}
+	if signer := a.msgSigner(creator(raw[:10]), ""); signer != "" {
+		t.Errorf("expected no signer for short bytes got %s", signer)
+	}
+}
+
+func TestTxRecord(t *testing.T) {
+	tx := &decodedTx{
+		signer:   "tarkeo1s",
+		feePayer: "tarkeo1f",
+		memo:     "m",
+		fee:      sdk.NewCoins(sdk.NewInt64Coin("uarkeo", 200), sdk.NewInt64Coin("uatom", 3)),
+	}
+	result := abcitypes.ResponseDeliverTx{GasWanted: 200000, GasUsed: 81234}
+	record := txRecord("ABC", 10, tx, result)
+	if record.Fee.String() != "200uarkeo" || record.FeeCoins != "200uarkeo,3uatom" {
+		t.Errorf("unexpected fee %s coins %s", record.Fee, record.FeeCoins)
+	}
+	if record.Signer != "tarkeo1s" || record.FeePayer != "tarkeo1f" || record.Memo != "m" {
+		t.Errorf("unexpected signer %+v", record)
+	}
+	if !record.Success || record.GasWanted != 200000 || record.GasUsed != 81234 {
+		t.Errorf("unexpected result %+v", record)
+	}
+
+	result.Code = 5
+	record = txRecord("ABC", 10, nil, result)
+	if record.Success || record.Code != 5 || record.Fee.String() != "0uarkeo" || record.Signer != "" {
+		t.Errorf("unexpected record of failed undecoded tx %+v", record)
+	}
+}
+
+func TestFailedTxRecords(t *testing.T) {
+	a := &IndexerApp{params: IndexerAppParams{Bech32PrefixAccAddr: "tarkeo", Bech32PrefixAccPub: "tarkeopub"}}
+	provider, err := bech32.ConvertAndEncode("tarkeopub", []byte{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("error encoding pubkey: %+v", err)
+	}
+	// creator, provider, service, an embedded coin and a varint
+	coin := protowire.AppendTag(nil, 1, protowire.BytesType)
+	coin = protowire.AppendString(coin, "uarkeo")
+	coin = protowire.AppendTag(coin, 2, protowire.BytesType)
+	coin = protowire.AppendString(coin, "100")
+	coin = append(coin, 0x1a, 0x01, 0x00)
+	open := protowire.AppendTag(nil, 1, protowire.BytesType)
+	open = protowire.AppendString(open, "tarkeo1creator")
+	open = protowire.AppendTag(open, 2, protowire.BytesType)
+	open = protowire.AppendString(open, provider)
+	open = protowire.AppendTag(open, 3, protowire.BytesType)
+	open = protowire.AppendString(open, "btc-mainnet-fullnode")
+	open = protowire.AppendTag(open, 9, protowire.BytesType)
+	open = protowire.AppendBytes(open, coin)
+	open = protowire.AppendTag(open, 7, protowire.VarintType)
+	open = protowire.AppendVarint(open, 100)
+
+	tx := &decodedTx{feePayer: "tarkeo1f", memo: "m", msgs: [][]*txMessage{
+		{{typeURL: "/cosmos.bank.v1beta1.MsgSend", signer: "tarkeo1f"}},
+		// a MsgExec wrapping a send then the open
+		{
+			{typeURL: "/cosmos.bank.v1beta1.MsgSend", signer: "tarkeo1creator", grantee: "tarkeo1g"},
+			{typeURL: "/arkeo.arkeo.MsgOpenContract", signer: "tarkeo1creator", grantee: "tarkeo1g", value: open},
+		},
+	}}
+	result := abcitypes.ResponseDeliverTx{Code: 1105, Codespace: "arkeo", Log: "contract already open"}
+	records := a.failedTxRecords("ABC", 10, tx, result)
+	if len(records) != 1 {
+		t.Fatalf("expected one arkeo message, got %d", len(records))
+	}
+	r := records[0]
+	if r.MsgIndex != 1 || r.ExecIndex != 1 || r.MsgType != "/arkeo.arkeo.MsgOpenContract" || r.Provider != provider {
+		t.Errorf("unexpected message %+v", r)
+	}
+	if r.Signer != "tarkeo1creator" || r.Grantee != "tarkeo1g" || r.FeePayer != "tarkeo1f" || r.Memo != "m" {
+		t.Errorf("unexpected signer %+v", r)
+	}
+	if r.Code != 1105 || r.Codespace != "arkeo" || r.Log != "contract already open" {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if r.Msg["3"] != "btc-mainnet-fullnode" || r.Msg["7"] != uint64(100) {
+		t.Errorf("unexpected fields %+v", r.Msg)
+	}
+	deposit, ok := r.Msg["9"].(map[string]interface{})
+	if !ok || deposit["1"] != "uarkeo" || deposit["2"] != "100" {
+		t.Errorf("unexpected embedded message %+v", r.Msg["9"])
+	}
+
+	tx.msgs = tx.msgs[:1]
+	if records = a.failedTxRecords("ABC", 10, tx, result); len(records) != 0 {
+		t.Errorf("expected no records of a tx without arkeo messages, got %+v", records)
+	}
+	if records = a.failedTxRecords("ABC", 10, nil, result); len(records) != 0 {
+		t.Errorf("expected no records of an undecoded tx, got %+v", records)
+	}
+}
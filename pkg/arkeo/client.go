@@ -0,0 +1,218 @@
+// Package arkeo is a client for the Arkeo module's REST query endpoints (ARKEO_API).
+package arkeo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultTimeout = 30 * time.Second
+	// page size requested from list endpoints
+	pageLimit = 200
+
+	pathProviders    = "/arkeo/providers"
+	pathContracts    = "/arkeo/contracts"
+	pathLatestHeight = "/cosmos/base/tendermint/v1beta1/blocks/latest"
+	pathAppliedPlan  = "/cosmos/upgrade/v1beta1/applied_plan/"
+)
+
+// Int decodes int64 values the REST gateway encodes as json strings, plain numbers are accepted too
+type Int int64
+
+func (i *Int) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "error parsing int %s", b)
+	}
+	*i = Int(v)
+	return nil
+}
+
+type Provider struct {
+	PubKey              string       `json:"pub_key"`
+	Chain               string       `json:"chain"`
+	MetadataURI         string       `json:"metadata_uri"`
+	MetadataNonce       Int          `json:"metadata_nonce"`
+	Status              string       `json:"status"`
+	MinContractDuration Int          `json:"min_contract_duration"`
+	MaxContractDuration Int          `json:"max_contract_duration"`
+	SubscriptionRate    types.Amount `json:"subscription_rate"`
+	PayAsYouGoRate      types.Amount `json:"pay_as_you_go_rate"`
+	Bond                types.Amount `json:"bond"`
+}
+
+type Contract struct {
+	ID             Int          `json:"id"`
+	ProviderPubKey string       `json:"provider_pub_key"`
+	Chain          string       `json:"chain"`
+	Client         string       `json:"client"`
+	Delegate       string       `json:"delegate"`
+	Type           string       `json:"type"`
+	Height         Int          `json:"height"`
+	Duration       Int          `json:"duration"`
+	Rate           types.Amount `json:"rate"`
+	Deposit        types.Amount `json:"deposit"`
+	Paid           types.Amount `json:"paid"`
+	Nonce          Int          `json:"nonce"`
+}
+
+// the key the directory stores contracts under, the delegate if set otherwise the client
+func (c *Contract) DelegatePubkey() string {
+	if c.Delegate != "" {
+		return c.Delegate
+	}
+	return c.Client
+}
+
+// open at height, a closed contract's duration is cut short to its close height
+func (c *Contract) IsOpen(height int64) bool {
+	return int64(c.Height)+int64(c.Duration) > height
+}
+
+type pagination struct {
+	NextKey string `json:"next_key"`
+}
+
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// Providers lists every provider, following pagination
+func (c *Client) Providers(ctx context.Context) ([]*Provider, error) {
+	providers := make([]*Provider, 0, pageLimit)
+	err := c.paginate(ctx, pathProviders, func(body []byte) (string, error) {
+		page := struct {
+			Provider   []*Provider `json:"provider"`
+			Pagination pagination  `json:"pagination"`
+		}{}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return "", errors.Wrapf(err, "error decoding providers")
+		}
+		providers = append(providers, page.Provider...)
+		return page.Pagination.NextKey, nil
+	})
+	return providers, err
+}
+
+// Contracts lists every contract the chain still holds, following pagination
+func (c *Client) Contracts(ctx context.Context) ([]*Contract, error) {
+	contracts := make([]*Contract, 0, pageLimit)
+	err := c.paginate(ctx, pathContracts, func(body []byte) (string, error) {
+		page := struct {
+			Contract   []*Contract `json:"contract"`
+			Pagination pagination  `json:"pagination"`
+		}{}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return "", errors.Wrapf(err, "error decoding contracts")
+		}
+		contracts = append(contracts, page.Contract...)
+		return page.Pagination.NextKey, nil
+	})
+	return contracts, err
+}
+
+// LatestHeight of the chain behind the api
+func (c *Client) LatestHeight(ctx context.Context) (int64, error) {
+	body, err := c.get(ctx, pathLatestHeight, nil)
+	if err != nil {
+		return 0, err
+	}
+	latest := struct {
+		Block struct {
+			Header struct {
+				Height Int `json:"height"`
+			} `json:"header"`
+		} `json:"block"`
+	}{}
+	if err = json.Unmarshal(body, &latest); err != nil {
+		return 0, errors.Wrapf(err, "error decoding latest block")
+	}
+	return int64(latest.Block.Header.Height), nil
+}
+
+// UpgradeHeight is the height the upgrade plan name was applied at, 0 when the chain hasn't applied it
+func (c *Client) UpgradeHeight(ctx context.Context, name string) (int64, error) {
+	body, err := c.get(ctx, pathAppliedPlan+url.PathEscape(name), nil)
+	if err != nil {
+		return 0, err
+	}
+	applied := struct {
+		Height Int `json:"height"`
+	}{}
+	if err = json.Unmarshal(body, &applied); err != nil {
+		return 0, errors.Wrapf(err, "error decoding applied plan %s", name)
+	}
+	return int64(applied.Height), nil
+}
+
+// paginate calls page with each page's body until it returns an empty next key
+func (c *Client) paginate(ctx context.Context, path string, page func(body []byte) (string, error)) error {
+	key := ""
+	for {
+		query := url.Values{"pagination.limit": {strconv.Itoa(pageLimit)}}
+		if key != "" {
+			query.Set("pagination.key", key)
+		}
+		body, err := c.get(ctx, path, query)
+		if err != nil {
+			return err
+		}
+		next, err := page(body)
+		if err != nil {
+			return err
+		}
+		if next == "" || next == key {
+			return nil
+		}
+		key = next
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating request")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error requesting %s", path)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %s", path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned http status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
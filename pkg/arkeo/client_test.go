@@ -0,0 +1,101 @@
+package arkeo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arkeonetwork/directory/pkg/types"
+)
+
+// stubAPI serves two pages of providers, one page of contracts and the latest block
+func stubAPI(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathProviders, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("pagination.key") {
+		case "":
+			fmt.Fprint(w, `{"provider":[{"pub_key":"pk1","chain":"btc-mainnet-fullnode","status":"ONLINE","bond":"100",
+				"metadata_nonce":"3","subscription_rate":"10","pay_as_you_go_rate":"2"}],"pagination":{"next_key":"AQ=="}}`)
+		case "AQ==":
+			fmt.Fprint(w, `{"provider":[{"pub_key":"pk2","chain":"eth-mainnet-fullnode","status":"OFFLINE","bond":"5",
+				"min_contract_duration":10}],"pagination":{"next_key":null}}`)
+		default:
+			t.Errorf("unexpected pagination key %q", r.URL.Query().Get("pagination.key"))
+		}
+	})
+	mux.HandleFunc(pathContracts, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"contract":[{"provider_pub_key":"pk1","chain":"btc-mainnet-fullnode","client":"c1",
+			"type":"SUBSCRIPTION","height":"100","duration":"50","rate":"10","nonce":"4"}],"pagination":{}}`)
+	})
+	mux.HandleFunc(pathLatestHeight, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"block":{"header":{"height":"1234"}}}`)
+	})
+	mux.HandleFunc(pathAppliedPlan, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == pathAppliedPlan+"v2" {
+			fmt.Fprint(w, `{"height":"1000"}`)
+			return
+		}
+		fmt.Fprint(w, `{"height":"0"}`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient(t *testing.T) {
+	srv := stubAPI(t)
+	defer srv.Close()
+	c := NewClient(srv.URL + "/")
+	ctx := context.Background()
+
+	providers, err := c.Providers(ctx)
+	if err != nil {
+		t.Fatalf("error listing providers: %+v", err)
+	}
+	if len(providers) != 2 {
+		t.Fatalf("expected 2 providers across pages got %d", len(providers))
+	}
+	if p := providers[0]; p.PubKey != "pk1" || p.MetadataNonce != 3 || !p.SubscriptionRate.Equal(types.NewAmount(10, types.DefaultDenom)) ||
+		p.Bond.String() != "100uarkeo" {
+		t.Errorf("unexpected provider %+v", p)
+	}
+	if providers[1].MinContractDuration != 10 {
+		t.Errorf("expected numeric duration 10 got %d", providers[1].MinContractDuration)
+	}
+
+	contracts, err := c.Contracts(ctx)
+	if err != nil {
+		t.Fatalf("error listing contracts: %+v", err)
+	}
+	if len(contracts) != 1 || contracts[0].DelegatePubkey() != "c1" || contracts[0].Nonce != 4 {
+		t.Fatalf("unexpected contracts %+v", contracts)
+	}
+	if !contracts[0].IsOpen(149) || contracts[0].IsOpen(150) {
+		t.Errorf("expected contract open through height 149")
+	}
+
+	height, err := c.LatestHeight(ctx)
+	if err != nil {
+		t.Fatalf("error finding height: %+v", err)
+	}
+	if height != 1234 {
+		t.Errorf("expected height 1234 got %d", height)
+	}
+
+	if height, err = c.UpgradeHeight(ctx, "v2"); err != nil || height != 1000 {
+		t.Errorf("expected upgrade v2 at 1000 got %d: %+v", height, err)
+	}
+	if height, err = c.UpgradeHeight(ctx, "v3"); err != nil || height != 0 {
+		t.Errorf("expected upgrade v3 unapplied got %d: %+v", height, err)
+	}
+}
+
+func TestClientHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not implemented", http.StatusNotImplemented)
+	}))
+	defer srv.Close()
+	if _, err := NewClient(srv.URL).Providers(context.Background()); err == nil {
+		t.Errorf("expected error for http 501")
+	}
+}
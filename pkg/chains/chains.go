@@ -0,0 +1,71 @@
+// Package chains holds the chain registry seed: the chains providers may serve and how to display them.
+package chains
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// the registry shipped with the directory, used when no registry file is configured
+//
+//go:embed chains.json
+var defaultRegistry []byte
+
+// chain identifiers as used by arkeo, e.g. btc-mainnet-fullnode
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9.\-]{0,127}$`)
+
+type Chain struct {
+	// identifier providers bond against
+	Chain       string
+	Name        string
+	Family      string
+	NetworkType string
+}
+
+// Load reads the registry seed from the json file at path, or the embedded default when path is empty
+func Load(path string) ([]*Chain, error) {
+	data := defaultRegistry
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, errors.Wrapf(err, "error reading chain registry %s", path)
+		}
+	}
+	chains := make([]*Chain, 0, 16)
+	if err := json.Unmarshal(data, &chains); err != nil {
+		return nil, errors.Wrapf(err, "error parsing chain registry")
+	}
+	seen := make(map[string]struct{}, len(chains))
+	for _, c := range chains {
+		if !ValidName(c.Chain) {
+			return nil, fmt.Errorf("invalid chain %q in registry", c.Chain)
+		}
+		if _, ok := seen[c.Chain]; ok {
+			return nil, fmt.Errorf("duplicate chain %q in registry", c.Chain)
+		}
+		seen[c.Chain] = struct{}{}
+	}
+	return chains, nil
+}
+
+// ValidName checks a chain identifier is well formed, not that the chain is registered
+func ValidName(chain string) bool {
+	return validName.MatchString(chain)
+}
+
+// FromName describes a chain first seen on-chain, taking family and network type from identifiers of the form
+// <family>-<network>-<service>
+func FromName(chain string) *Chain {
+	c := &Chain{Chain: chain, Name: chain}
+	if parts := strings.Split(chain, "-"); len(parts) >= 3 {
+		c.Family = parts[0]
+		c.NetworkType = parts[1]
+	}
+	return c
+}
@@ -0,0 +1,54 @@
+package chains
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDefault(t *testing.T) {
+	chains, err := Load("")
+	if err != nil {
+		t.Fatalf("error loading default registry: %+v", err)
+	}
+	found := false
+	for _, c := range chains {
+		if c.Chain == "btc-mainnet-fullnode" {
+			found = c.Name == "Bitcoin" && c.NetworkType == "mainnet"
+		}
+	}
+	if !found {
+		t.Errorf("expected btc-mainnet-fullnode in the default registry")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, tc := range map[string]struct {
+		json string
+		ok   bool
+	}{
+		"valid":     {`[{"Chain":"sol-mainnet-fullnode","Name":"Solana"}]`, true},
+		"duplicate": {`[{"Chain":"a-b-c"},{"Chain":"a-b-c"}]`, false},
+		"invalid":   {`[{"Chain":"Not A Chain"}]`, false},
+		"malformed": {`{`, false},
+	} {
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, []byte(tc.json), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); (err == nil) != tc.ok {
+			t.Errorf("%s: expected ok %t got err %v", name, tc.ok, err)
+		}
+	}
+}
+
+func TestFromName(t *testing.T) {
+	c := FromName("sol-devnet-rpc-archive")
+	if c.Family != "sol" || c.NetworkType != "devnet" || c.Name != "sol-devnet-rpc-archive" {
+		t.Errorf("unexpected chain %+v", c)
+	}
+	if c = FromName("swapi.dev"); c.Family != "" || c.NetworkType != "" {
+		t.Errorf("expected no family or network for swapi.dev got %+v", c)
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/pkg/errors"
+)
+
+// record the account address derived from pubkey, a pubkey's address never changes
+func (d *DirectoryDB) UpsertPubkeyAddress(pubkey, address string) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlUpsertPubkeyAddress, pubkey, address)
+}
+
+// find the pubkey whose account address is address, empty if no provider or client with it has been indexed
+func (d *DirectoryDB) FindPubkeyByAddress(address string) (string, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return "", errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	var result struct {
+		Pubkey string `db:"pubkey"`
+	}
+	if err = selectOne(conn, sqlFindPubkeyByAddress, &result, address); err != nil {
+		return "", errors.Wrapf(err, "error selecting")
+	}
+	return result.Pubkey, nil
+}
+
+// provider, client and delegate pubkeys with no recorded address
+func (d *DirectoryDB) FindPubkeysWithoutAddress() ([]string, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]string, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindPubkeysWithoutAddress); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
@@ -0,0 +1,24 @@
+package db
+
+const (
+	sqlUpsertPubkeyAddress = `
+	insert into pubkey_addresses(pubkey,address)
+	values ($1,$2)
+	on conflict on constraint pubkey_addresses_pubkey_key
+	do update set address = $2, updated = now()
+	where pubkey_addresses.pubkey = $1
+	returning id, created, updated
+	`
+	sqlFindPubkeyByAddress = `
+	select pubkey from pubkey_addresses where address = $1 order by id limit 1
+	`
+	sqlFindPubkeysWithoutAddress = `
+	select k.pubkey
+	from (
+		select pubkey from providers
+		union select client_pubkey from contracts
+		union select delegate_pubkey from contracts
+	) k
+	where not exists (select 1 from pubkey_addresses pa where pa.pubkey = k.pubkey)
+	`
+)
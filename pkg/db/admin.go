@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/pkg/errors"
+)
+
+// rows removed from one table by DeleteIndexedRange
+type RangeDeletion struct {
+	Table string
+	Rows  int64
+}
+
+// DeleteIndexedRange removes blocks and events indexed from heights from through to inclusive and reopens contracts
+// closed in the range, so replaying the range restores them. Providers and contracts themselves are kept as later
+// events may reference them, see RebuildProjections
+func (d *DirectoryDB) DeleteIndexedRange(from, to int64) ([]*RangeDeletion, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		return nil, errors.Wrapf(err, "error beginning transaction")
+	}
+	defer tx.Rollback(context.Background()) // no-op after commit
+
+	deletions := make([]*RangeDeletion, 0, len(sqlDeleteIndexedRange))
+	for _, stmt := range sqlDeleteIndexedRange {
+		tag, err := tx.Exec(context.Background(), stmt.sql, from, to)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error deleting %s from %d to %d", stmt.table, from, to)
+		}
+		deletions = append(deletions, &RangeDeletion{Table: stmt.table, Rows: tag.RowsAffected()})
+	}
+	if err = tx.Commit(context.Background()); err != nil {
+		return nil, errors.Wrapf(err, "error committing")
+	}
+	return deletions, nil
+}
+
+// RebuildProjections resets provider and contract state from their latest events. Replaying a range applies its
+// events in order, which leaves providers with the state of the range's last event rather than the latest one
+func (d *DirectoryDB) RebuildProjections() error {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	for _, sql := range []string{sqlRebuildProviderMods, sqlRebuildProviderBonds, sqlRebuildContractsClosed} {
+		if _, err = conn.Exec(context.Background(), sql); err != nil {
+			return errors.Wrapf(err, "error rebuilding projections")
+		}
+	}
+	return nil
+}
+
+// result of one consistency check, Count rows violate it
+type ConsistencyCheck struct {
+	Check       string `db:"check_name"`
+	Description string `db:"description"`
+	Count       int64  `db:"count"`
+}
+
+func (c *ConsistencyCheck) OK() bool {
+	return c.Count == 0
+}
+
+// Verify runs consistency checks between events and the state derived from them
+func (d *DirectoryDB) Verify() ([]*ConsistencyCheck, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	results := make([]*ConsistencyCheck, 0, 8)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlVerify); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
@@ -0,0 +1,111 @@
+package db
+
+// statements run by DeleteIndexedRange with $1, $2 the inclusive height range, contracts closed in the range first
+var sqlDeleteIndexedRange = []struct {
+	table string
+	sql   string
+}{
+	{"contracts", `update contracts set closed_height = 0, updated = now() where closed_height between $1 and $2`},
+	{"provider_bond_events", `delete from provider_bond_events where height between $1 and $2`},
+	{"provider_mod_events", `delete from provider_mod_events where height between $1 and $2`},
+	{"open_contract_events", `delete from open_contract_events where height between $1 and $2`},
+	{"close_contract_events", `delete from close_contract_events where height between $1 and $2`},
+	{"contract_settlement_events", `delete from contract_settlement_events where height between $1 and $2`},
+	{"validator_payout_events", `delete from validator_payout_events where height between $1 and $2`},
+	{"txs", `delete from txs where height between $1 and $2`},
+	{"failed_txs", `delete from failed_txs where height between $1 and $2`},
+	{"blocks", `delete from blocks where height between $1 and $2`},
+}
+
+const (
+	sqlRebuildProviderMods = `
+	update providers p
+	set metadata_uri = m.metadata_uri,
+	    metadata_nonce = m.metadata_nonce,
+	    status = m.status,
+	    min_contract_duration = m.min_contract_duration,
+	    max_contract_duration = m.max_contract_duration,
+	    subscription_rate = m.subscription_rate,
+	    paygo_rate = m.paygo_rate,
+	    updated = now()
+	from (
+		select distinct on (provider_id) *
+		from provider_mod_events
+		order by provider_id, height desc, id desc
+	) m
+	where m.provider_id = p.id
+	`
+	sqlRebuildProviderBonds = `
+	update providers p
+	set bond = b.bond_abs,
+	    updated = now()
+	from (
+		select distinct on (provider_id) provider_id, bond_abs
+		from provider_bond_events
+		order by provider_id, height desc, id desc
+	) b
+	where b.provider_id = p.id and p.bond != b.bond_abs
+	`
+	sqlRebuildContractsClosed = `
+	update contracts c
+	set closed_height = e.height,
+	    updated = now()
+	from (
+		select contract_id, max(height) as height
+		from close_contract_events
+		group by contract_id
+	) e
+	where e.contract_id = c.id and c.closed_height != e.height
+	`
+
+	sqlVerify = `
+	select 'providers_without_bond' as check_name,
+	       'providers with no bond event' as description,
+	       count(1) as count
+	from providers p
+	where not exists (select 1 from provider_bond_events e where e.provider_id = p.id)
+	union all
+	select 'provider_mod_state',
+	       'providers whose status or metadata differ from their latest mod event',
+	       count(1)
+	from providers p
+	join (
+		select distinct on (provider_id) *
+		from provider_mod_events
+		order by provider_id, height desc, id desc
+	) m on m.provider_id = p.id
+	where p.status is distinct from m.status
+	   or p.metadata_uri is distinct from m.metadata_uri
+	   or p.metadata_nonce is distinct from m.metadata_nonce
+	union all
+	select 'contract_closed_height',
+	       'contracts whose closed height differs from their close events',
+	       count(1)
+	from contracts c
+	left join (
+		select contract_id, max(height) as height
+		from close_contract_events
+		group by contract_id
+	) e on e.contract_id = c.id
+	where c.closed_height != coalesce(e.height, 0)
+	union all
+	select 'contracts_without_open',
+	       'contracts with no open event',
+	       count(1)
+	from contracts c
+	where not exists (select 1 from open_contract_events e where e.contract_id = c.id)
+	union all
+	select 'events_without_block',
+	       'event heights with no stored block',
+	       count(distinct h.height)
+	from (
+		select height from provider_bond_events
+		union all select height from provider_mod_events
+		union all select height from open_contract_events
+		union all select height from close_contract_events
+		union all select height from contract_settlement_events
+		union all select height from validator_payout_events
+	) h
+	where not exists (select 1 from blocks b where b.height = h.height)
+	`
+)
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, err := New(config)
+	if err != nil {
+		t.Errorf("error getting db: %+v", err)
+		t.FailNow()
+	}
+
+	checks, err := db.Verify()
+	if err != nil {
+		t.Errorf("error verifying: %+v", err)
+		t.FailNow()
+	}
+	if len(checks) == 0 {
+		t.Errorf("expected consistency checks")
+	}
+	for _, check := range checks {
+		log.Infof("%s: %d", check.Check, check.Count)
+	}
+}
+
+func TestDeleteIndexedRange(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, err := New(config)
+	if err != nil {
+		t.Errorf("error getting db: %+v", err)
+		t.FailNow()
+	}
+
+	// far beyond any indexed height, nothing to delete
+	deletions, err := db.DeleteIndexedRange(1e15, 1e15+10)
+	if err != nil {
+		t.Errorf("error deleting range: %+v", err)
+		t.FailNow()
+	}
+	for _, del := range deletions {
+		if del.Rows != 0 {
+			t.Errorf("expected no %s rows deleted, got %d", del.Table, del.Rows)
+		}
+	}
+	if err = db.RebuildProjections(); err != nil {
+		t.Errorf("error rebuilding projections: %+v", err)
+	}
+}
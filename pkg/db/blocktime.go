@@ -0,0 +1,106 @@
+package db
+
+import (
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// BlockClock converts between heights and wall-clock time, anchored at the latest stored block and extrapolated
+// with the rolling average block time
+type BlockClock struct {
+	RefHeight int64     `db:"ref_height"`
+	RefTime   time.Time `db:"ref_time"`
+	// seconds per block
+	AvgBlockTime float64 `db:"avg_block_time"`
+}
+
+// recompute the indexer's average block time over the most recent window blocks. returns nil if there are too
+// few blocks to average
+func (d *DirectoryDB) UpdateAverageBlockTime(indexerID int64, window int64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	entity, err := update(conn, sqlUpdateAverageBlockTime, indexerID, window)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return entity, err
+}
+
+// find the clock, nil until there are blocks and an average block time
+func (d *DirectoryDB) FindBlockClock() (*BlockClock, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	clock := BlockClock{}
+	if err = selectOne(conn, sqlFindBlockClock, &clock); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if clock.RefHeight == 0 || clock.AvgBlockTime <= 0 {
+		return nil, nil
+	}
+	return &clock, nil
+}
+
+// find the last block at or before t, nil if t precedes all stored blocks
+func (d *DirectoryDB) FindBlockAtTime(t time.Time) (*Block, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	block := &Block{}
+	if err = selectOne(conn, sqlFindBlockAtTime, block, t); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if block.Height == 0 {
+		return nil, nil
+	}
+	return block, nil
+}
+
+func (d *DirectoryDB) FindBlock(height int64) (*Block, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	block := &Block{}
+	if err = selectOne(conn, sqlFindBlock, block, height); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if block.Height == 0 {
+		return nil, nil
+	}
+	return block, nil
+}
+
+// estimated time of height
+func (c *BlockClock) TimeAt(height int64) time.Time {
+	return c.RefTime.Add(c.Duration(height - c.RefHeight))
+}
+
+// estimated height at t, the latest block at or before it
+func (c *BlockClock) HeightAt(t time.Time) int64 {
+	blocks := t.Sub(c.RefTime).Seconds() / c.AvgBlockTime
+	return c.RefHeight + int64(math.Floor(blocks))
+}
+
+// estimated wall-clock duration of a number of blocks
+func (c *BlockClock) Duration(blocks int64) time.Duration {
+	return time.Duration(float64(blocks) * c.AvgBlockTime * float64(time.Second))
+}
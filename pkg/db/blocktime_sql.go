@@ -0,0 +1,37 @@
+package db
+
+const (
+	// inserts nothing until there are two blocks to average over
+	sqlUpdateAverageBlockTime = `
+	insert into avg_block_time(id,avg_block_time,window_blocks)
+	select $1, x.avg_block_time, $2
+	from (select extract(epoch from max(b.block_time) - min(b.block_time)) / nullif(max(b.height) - min(b.height), 0) as avg_block_time
+	      from (select height, block_time from blocks order by height desc limit $2) b) x
+	where x.avg_block_time > 0
+	on conflict on constraint avg_block_time_pk
+	do update set avg_block_time = excluded.avg_block_time,
+	              window_blocks = excluded.window_blocks,
+	              updated = now()
+	returning id, created, updated
+	`
+	sqlFindBlockClock = `
+	select b.height as ref_height,
+	       b.block_time as ref_time,
+	       coalesce(s.avg_block_time,0) as avg_block_time
+	from blocks b
+		left join (select avg_block_time from avg_block_time order by id limit 1) s on true
+	where b.height = (select max(height) from blocks)
+	`
+	sqlFindBlockAtTime = `
+	select ` + blockCols + `
+	from blocks b
+	where b.block_time <= $1
+	order by b.block_time desc, b.height desc
+	limit 1
+	`
+	sqlFindBlock = `
+	select ` + blockCols + `
+	from blocks b
+	where b.height = $1
+	`
+)
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockClock(t *testing.T) {
+	ref := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &BlockClock{RefHeight: 1000, RefTime: ref, AvgBlockTime: 5}
+
+	if actual := clock.TimeAt(1100); !actual.Equal(ref.Add(500 * time.Second)) {
+		t.Errorf("expected %s got %s", ref.Add(500*time.Second), actual)
+	}
+	if actual := clock.TimeAt(900); !actual.Equal(ref.Add(-500 * time.Second)) {
+		t.Errorf("expected %s got %s", ref.Add(-500*time.Second), actual)
+	}
+	if actual := clock.HeightAt(ref.Add(12 * time.Second)); actual != 1002 {
+		t.Errorf("expected 1002 got %d", actual)
+	}
+	if actual := clock.HeightAt(ref.Add(-12 * time.Second)); actual != 997 {
+		t.Errorf("expected 997 got %d", actual)
+	}
+	if actual := clock.Duration(720); actual != time.Hour {
+		t.Errorf("expected 1h got %s", actual)
+	}
+}
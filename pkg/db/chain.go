@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arkeonetwork/directory/pkg/chains"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/pkg/errors"
+)
+
+const (
+	ChainSourceConfig   = "config"
+	ChainSourceObserved = "observed"
+)
+
+type Chain struct {
+	Entity          `json:"-"`
+	Chain           string `db:"chain"`
+	Name            string `db:"name"`
+	Family          string `db:"family"`
+	NetworkType     string `db:"network_type"`
+	Source          string `db:"source"`
+	FirstSeenHeight *int64 `db:"first_seen_height"`
+}
+
+// swagger:model ChainSummary
+type ChainSummary struct {
+	Chain
+	ProviderCount       int64 `db:"provider_count"`
+	ContractCount       int64 `db:"contract_count"`
+	OpenContractCount   int64 `db:"open_contract_count"`
+	OnlineProviderCount int64 `db:"online_provider_count"`
+}
+
+// UpsertConfiguredChain registers a chain from the registry seed, its display metadata replaces any existing
+func (d *DirectoryDB) UpsertConfiguredChain(c *chains.Chain) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlUpsertConfiguredChain, c.Chain, c.Name, c.Family, c.NetworkType)
+}
+
+// RegisterObservedChain registers a chain first bonded against at height, a registered chain is left unchanged
+// other than recording the height it was first seen at
+func (d *DirectoryDB) RegisterObservedChain(c *chains.Chain, height int64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlRegisterObservedChain, c.Chain, c.Name, c.Family, c.NetworkType, height)
+}
+
+func (d *DirectoryDB) FindChain(chain string) (*Chain, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	c := Chain{}
+	if err = selectOne(conn, sqlFindChain, &c, chain); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if c.ID == 0 {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// FindChains lists registered chains with their provider and contract counts
+func (d *DirectoryDB) FindChains() ([]*ChainSummary, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ChainSummary, 0, 16)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindChains); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
@@ -0,0 +1,38 @@
+package db
+
+const (
+	chainCols = `
+	ch.id,
+	ch.created,
+	ch.updated,
+	ch.chain,
+	ch.name,
+	ch.family,
+	ch.network_type,
+	ch.source,
+	ch.first_seen_height
+	`
+	sqlUpsertConfiguredChain = `
+	insert into chains(chain,name,family,network_type,source)
+	values ($1,$2,$3,$4,'config')
+	on conflict on constraint chains_chain_key
+	do update set name = $2, family = $3, network_type = $4, source = 'config', updated = now()
+	returning id, created, updated
+	`
+	sqlRegisterObservedChain = `
+	insert into chains(chain,name,family,network_type,source,first_seen_height)
+	values ($1,$2,$3,$4,'observed',$5)
+	on conflict on constraint chains_chain_key
+	do update set first_seen_height = least(coalesce(chains.first_seen_height, $5), $5), updated = now()
+	returning id, created, updated
+	`
+	sqlFindChain  = `select ` + chainCols + ` from chains ch where ch.chain = $1`
+	sqlFindChains = `select ` + chainCols + `,
+	(select count(1) from providers p where p.chain = ch.chain) as provider_count,
+	(select count(1) from providers p where p.chain = ch.chain and p.status = 'Online') as online_provider_count,
+	(select count(1) from providers p join contracts c on c.provider_id = p.id where p.chain = ch.chain) as contract_count,
+	(select count(1) from providers p join open_contracts_v c on c.provider_id = p.id where p.chain = ch.chain) as open_contract_count
+	from chains ch
+	order by ch.chain
+	`
+)
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/arkeonetwork/directory/pkg/types"
 	"github.com/georgysavva/scany/pgxscan"
@@ -10,15 +11,64 @@ import (
 
 type ArkeoContract struct {
 	Entity
-	ProviderID     int64              `db:"provider_id"`
-	DelegatePubkey string             `db:"delegate_pubkey"`
-	ClientPubkey   string             `db:"client_pubkey"`
-	Height         int64              `db:"height"`
-	ContractType   types.ContractType `db:"contract_type"`
-	Duration       int64              `db:"duration"`
-	Rate           int64              `db:"rate"`
-	OpenCost       int64              `db:"open_cost"`
-	ClosedHeight   int64              `db:"closed_height"`
+	// the chain's identifier of the contract, 0 when its events did not carry one
+	ContractID     uint64 `db:"contract_id"`
+	ProviderID     int64  `db:"provider_id"`
+	DelegatePubkey string `db:"delegate_pubkey"`
+	ClientPubkey   string `db:"client_pubkey"`
+	// account addresses derived from the pubkeys, empty until the indexer has recorded them
+	DelegateAddress string             `db:"delegate_address"`
+	ClientAddress   string             `db:"client_address"`
+	Height          int64              `db:"height"`
+	ContractType    types.ContractType `db:"contract_type"`
+	Duration        int64              `db:"duration"`
+	Rate            types.Amount       `db:"rate"`
+	OpenCost        types.Amount       `db:"open_cost"`
+	ClosedHeight    int64              `db:"closed_height"`
+}
+
+// a provider's contract with the time of the block it was opened in, nil if that block isn't stored
+type ProviderContract struct {
+	ArkeoContract
+	OpenedBlockTime *time.Time `db:"opened_block_time" json:"-"`
+}
+
+// a client's contract with the provider it is with
+type ClientContract struct {
+	ArkeoContract
+	ProviderPubkey  string `db:"provider_pubkey"`
+	ProviderAddress string `db:"provider_address"`
+	Chain           string `db:"chain"`
+}
+
+// find all contracts a pubkey is the client or delegate of, newest first
+func (d *DirectoryDB) FindClientContracts(pubkey string) ([]*ClientContract, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ClientContract, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindClientContracts, pubkey); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+
+	return results, nil
+}
+
+// find all contracts of a provider, newest first
+func (d *DirectoryDB) FindProviderContracts(pubkey string, chain string) ([]*ProviderContract, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ProviderContract, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindProviderContracts, chain, pubkey); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+
+	return results, nil
 }
 
 func (d *DirectoryDB) FindContract(providerID int64, delegatePubkey string, height int64) (*ArkeoContract, error) {
@@ -40,21 +90,27 @@ func (d *DirectoryDB) FindContract(providerID int64, delegatePubkey string, heig
 	return &contract, nil
 }
 
-func (d *DirectoryDB) FindContractsByPubKeys(chain string, providerPubkey string, delegatePubkey string) ([]*ArkeoContract, error) {
+func (d *DirectoryDB) FindContractByID(id int64) (*ArkeoContract, error) {
 	conn, err := d.getConnection()
 	defer conn.Release()
 	if err != nil {
 		return nil, errors.Wrapf(err, "error obtaining db connection")
 	}
-	results := make([]*ArkeoContract, 0, 128)
-	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindContractsByPubKeys, chain, providerPubkey, delegatePubkey); err != nil {
-		return nil, errors.Wrapf(err, "error scanning")
+
+	contract := ArkeoContract{}
+	if err = selectOne(conn, sqlFindContractByID, &contract, id); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
 	}
 
-	return results, nil
+	// not found
+	if contract.ID == 0 {
+		return nil, nil
+	}
+	return &contract, nil
 }
 
-func (d *DirectoryDB) FindContractByPubKeys(chain string, providerPubkey string, delegatePubkey string, height int64) (*ArkeoContract, error) {
+// find a contract by the chain's contract id, nil if no contract with it has been indexed
+func (d *DirectoryDB) FindContractByContractID(contractID uint64) (*ArkeoContract, error) {
 	conn, err := d.getConnection()
 	defer conn.Release()
 	if err != nil {
@@ -62,7 +118,7 @@ func (d *DirectoryDB) FindContractByPubKeys(chain string, providerPubkey string,
 	}
 
 	contract := ArkeoContract{}
-	if err = selectOne(conn, sqlFindContractByPubKeys, &contract, chain, providerPubkey, delegatePubkey, height); err != nil {
+	if err = selectOne(conn, sqlFindContractByContractID, &contract, contractID); err != nil {
 		return nil, errors.Wrapf(err, "error selecting")
 	}
 
@@ -73,6 +129,32 @@ func (d *DirectoryDB) FindContractByPubKeys(chain string, providerPubkey string,
 	return &contract, nil
 }
 
+// record the chain's contract id of a contract indexed without one
+func (d *DirectoryDB) SetContractID(id int64, contractID uint64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return update(conn, sqlSetContractID, contractID, id)
+}
+
+func (d *DirectoryDB) FindContractsByPubKeys(chain string, providerPubkey string, delegatePubkey string) ([]*ArkeoContract, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ArkeoContract, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindContractsByPubKeys, chain, providerPubkey, delegatePubkey); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+
+	return results, nil
+}
+
+// rate and open cost are stored as 0 when the event left them out, the columns are not null
 func (d *DirectoryDB) UpsertContract(providerID int64, evt types.OpenContractEvent) (*Entity, error) {
 	conn, err := d.getConnection()
 	defer conn.Release()
@@ -81,7 +163,7 @@ func (d *DirectoryDB) UpsertContract(providerID int64, evt types.OpenContractEve
 	}
 
 	return upsert(conn, sqlUpsertContract, providerID, evt.GetDelegatePubkey(), evt.ClientPubkey, evt.ContractType,
-		evt.Duration, evt.Rate, evt.OpenCost, evt.Height)
+		evt.Duration, evt.Rate.OrZero(), evt.OpenCost.OrZero(), evt.Height, evt.ContractID)
 }
 
 func (d *DirectoryDB) CloseContract(contractID int64, height int64) (*Entity, error) {
@@ -102,7 +184,7 @@ func (d *DirectoryDB) UpsertContractSettlementEvent(contractID int64, evt types.
 	}
 
 	return upsert(conn, sqlUpsertContractSettlementEvent, contractID, evt.TxID, evt.ClientPubkey, evt.EventHeight,
-		evt.Nonce, evt.Paid, evt.Reserve)
+		evt.Nonce, evt.Paid, evt.Reserve, evt.Signer, evt.Grantee, evt.FeePayer, evt.Memo)
 }
 
 func (d *DirectoryDB) UpsertOpenContractEvent(contractID int64, evt types.OpenContractEvent) (*Entity, error) {
@@ -113,7 +195,7 @@ func (d *DirectoryDB) UpsertOpenContractEvent(contractID int64, evt types.OpenCo
 	}
 
 	return upsert(conn, sqlUpsertOpenContractEvent, contractID, evt.ClientPubkey, evt.ContractType, evt.EventHeight, evt.TxID,
-		evt.Duration, evt.Rate, evt.OpenCost)
+		evt.Duration, evt.Rate.OrZero(), evt.OpenCost.OrZero(), evt.MsgIndex, evt.EventIndex, evt.Signer, evt.Grantee, evt.FeePayer, evt.Memo)
 }
 
 func (d *DirectoryDB) UpsertCloseContractEvent(contractID int64, evt types.CloseContractEvent) (*Entity, error) {
@@ -123,5 +205,6 @@ func (d *DirectoryDB) UpsertCloseContractEvent(contractID int64, evt types.Close
 		return nil, errors.Wrapf(err, "error obtaining db connection")
 	}
 
-	return upsert(conn, sqlUpsertCloseContractEvent, contractID, evt.ClientPubkey, evt.GetDelegatePubkey(), evt.EventHeight, evt.TxID)
+	return upsert(conn, sqlUpsertCloseContractEvent, contractID, evt.ClientPubkey, evt.GetDelegatePubkey(), evt.EventHeight, evt.TxID,
+		evt.MsgIndex, evt.EventIndex, evt.Signer, evt.Grantee, evt.FeePayer, evt.Memo)
 }
@@ -5,6 +5,7 @@ const (
 	c.id,
 	c.created,
 	c.updated,
+	coalesce(c.contract_id,0) as contract_id,
 	c.provider_id,
 	c.delegate_pubkey,
 	c.client_pubkey,
@@ -13,7 +14,9 @@ const (
 	c.duration,
 	c.rate,
 	c.open_cost,
-	c.closed_height
+	c.closed_height,
+	coalesce((select pa.address from pubkey_addresses pa where pa.pubkey = c.delegate_pubkey),'') as delegate_address,
+	coalesce((select pa.address from pubkey_addresses pa where pa.pubkey = c.client_pubkey),'') as client_address
 	`
 )
 
@@ -34,7 +37,9 @@ const (
 		  and c.delegate_pubkey = $2
 			and c.height = $3
 	`
-	sqlFindContractsByPubKeys = `select ` + contractCols + `
+	sqlFindContractByID         = `select ` + contractCols + ` from contracts c where c.id = $1`
+	sqlFindContractByContractID = `select ` + contractCols + ` from contracts c where c.contract_id = $1`
+	sqlFindContractsByPubKeys   = `select ` + contractCols + `
 	-- c.id,
 	-- c.created,
 	-- c.updated,
@@ -49,20 +54,39 @@ const (
 	where p.chain = $1 and p.pubkey = $2 and c.delegate_pubkey = $3
 	order by c.id desc
 	`
-	sqlFindContractByPubKeys = `select ` + contractCols + `
+	sqlFindProviderContracts = `select ` + contractCols + `,
+	ob.block_time as opened_block_time
+	from providers p
+		join contracts c on p.id = c.provider_id
+		left join blocks ob on ob.height = c.height
+	where p.chain = $1 and p.pubkey = $2
+	order by c.height desc, c.id desc
+	`
+	sqlFindClientContracts = `select ` + contractCols + `,
+	p.pubkey as provider_pubkey,
+	coalesce((select pa.address from pubkey_addresses pa where pa.pubkey = p.pubkey),'') as provider_address,
+	p.chain
 	from providers p join contracts c on p.id = c.provider_id
-	where p.chain = $1 and p.pubkey = $2 and c.delegate_pubkey = $3 and c.height = $4
+	where c.client_pubkey = $1 or c.delegate_pubkey = $1
+	order by c.height desc, c.id desc
 	`
 	sqlUpsertContract = `
-		insert into contracts(provider_id,delegate_pubkey,client_pubkey,contract_type,duration,rate,open_cost,height)
-		values ($1,$2,$3,$4,$5,$6,$7,$8)
+		insert into contracts(provider_id,delegate_pubkey,client_pubkey,contract_type,duration,rate,open_cost,height,contract_id)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,nullif($9::bigint,0))
 		on conflict on constraint contracts_provider_delegate_height_key
-		do update set contract_type = $4, duration = $5, rate = $6, open_cost = $7, updated = now()
+		do update set contract_type = $4, duration = $5, rate = $6, open_cost = $7,
+		              contract_id = coalesce(nullif($9::bigint,0), contracts.contract_id), updated = now()
 		where contracts.provider_id = $1
 		  and contracts.delegate_pubkey = $2
 			and contracts.height = $8
 		returning id, created, updated
 	`
+	sqlSetContractID = `
+	update contracts
+	set contract_id = $1, updated = now()
+	where id = $2
+	returning id, created, updated
+	`
 	sqlCloseContract = `
 	update contracts
 	set closed_height = $1
@@ -70,27 +94,45 @@ const (
 	returning id, created, updated
 	`
 	sqlUpsertOpenContractEvent = `
-	insert into open_contract_events(contract_id,client_pubkey,contract_type,height,txid,duration,rate,open_cost)
-	values ($1,$2,$3,$4,$5,$6,$7,$8)
-	on conflict on constraint open_contract_events_txid_unq
-	do update set updated = now()
+	insert into open_contract_events(contract_id,client_pubkey,contract_type,height,txid,duration,rate,open_cost,msg_index,event_index,
+		signer,exec_grantee,fee_payer,memo)
+	values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,nullif($11,''),nullif($12,''),nullif($13,''),nullif($14,''))
+	on conflict on constraint open_contract_events_txid_msg_evt_key
+	do update set signer = coalesce(open_contract_events.signer, excluded.signer),
+	              exec_grantee = coalesce(open_contract_events.exec_grantee, excluded.exec_grantee),
+	              fee_payer = coalesce(open_contract_events.fee_payer, excluded.fee_payer),
+	              memo = coalesce(open_contract_events.memo, excluded.memo),
+	              updated = now()
 	where open_contract_events.txid = $5
+	  and open_contract_events.msg_index = $9
+	  and open_contract_events.event_index = $10
 	returning id, created, updated
 	`
 	sqlUpsertCloseContractEvent = `
-	insert into close_contract_events(contract_id,client_pubkey,delegate_pubkey,height,txid)
-	values ($1,$2,$3,$4,$5)
-	on conflict on constraint close_contract_events_txid_key
-	do update set updated = now()
+	insert into close_contract_events(contract_id,client_pubkey,delegate_pubkey,height,txid,msg_index,event_index,
+		signer,exec_grantee,fee_payer,memo)
+	values ($1,$2,$3,$4,$5,$6,$7,nullif($8,''),nullif($9,''),nullif($10,''),nullif($11,''))
+	on conflict on constraint close_contract_events_txid_msg_evt_key
+	do update set signer = coalesce(close_contract_events.signer, excluded.signer),
+	              exec_grantee = coalesce(close_contract_events.exec_grantee, excluded.exec_grantee),
+	              fee_payer = coalesce(close_contract_events.fee_payer, excluded.fee_payer),
+	              memo = coalesce(close_contract_events.memo, excluded.memo),
+	              updated = now()
 	where close_contract_events.txid = $5
+	  and close_contract_events.msg_index = $6
+	  and close_contract_events.event_index = $7
 	returning id, created, updated
 	`
 
 	sqlUpsertContractSettlementEvent = `
-	insert into contract_settlement_events(contract_id,txid,client_pubkey,height,nonce,paid,reserve)
-	values ($1,$2,$3,$4,$5,$6,$7)
+	insert into contract_settlement_events(contract_id,txid,client_pubkey,height,nonce,paid,reserve,signer,exec_grantee,fee_payer,memo)
+	values ($1,$2,$3,$4,$5,$6,$7,nullif($8,''),nullif($9,''),nullif($10,''),nullif($11,''))
 	on conflict on constraint contract_settlement_contract_nonce_key
-	do update set updated = now()
+	do update set signer = coalesce(contract_settlement_events.signer, excluded.signer),
+	              exec_grantee = coalesce(contract_settlement_events.exec_grantee, excluded.exec_grantee),
+	              fee_payer = coalesce(contract_settlement_events.fee_payer, excluded.fee_payer),
+	              memo = coalesce(contract_settlement_events.memo, excluded.memo),
+	              updated = now()
 	where contract_settlement_events.contract_id = $1
 	  and contract_settlement_events.nonce = $5
 	returning id, created, updated
@@ -22,7 +22,10 @@ type DBConfig struct {
 }
 
 type DirectoryDB struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	timer *queryTimer
+	// parent of query spans, see WithContext
+	ctx context.Context
 }
 
 // base entity for db types
@@ -36,7 +39,26 @@ var log = logging.WithoutFields()
 
 // obtain a db connection, callers must call conn.Release() when finished to return the conn to the pool
 func (d *DirectoryDB) getConnection() (*pgxpool.Conn, error) {
-	return d.pool.Acquire(context.Background())
+	ctx, c := d.timer.begin(d.context(), 1)
+	conn, err := d.pool.Acquire(ctx)
+	d.timer.acquired(c, conn, err)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// WithContext returns a DirectoryDB sharing this pool whose queries are traced as children of ctx and
+// give up waiting for a connection when ctx is done
+func (d *DirectoryDB) WithContext(ctx context.Context) *DirectoryDB {
+	return &DirectoryDB{pool: d.pool, timer: d.timer, ctx: ctx}
+}
+
+func (d *DirectoryDB) context() context.Context {
+	if d.ctx == nil {
+		return context.Background()
+	}
+	return d.ctx
 }
 
 func New(config DBConfig) (*DirectoryDB, error) {
@@ -47,11 +69,15 @@ func New(config DBConfig) (*DirectoryDB, error) {
 		return nil, errors.Wrapf(err, "error parsing url to config from: \"%s\"", url)
 	}
 
+	timer := &queryTimer{}
+	poolConfig.AfterRelease = timer.release
+
 	pool, err := pgxpool.ConnectConfig(context.Background(), poolConfig)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error connecting to db")
 	}
 
 	log.Infof("connected pool for db %s on %s:%d", config.DBName, config.Host, config.Port)
-	return &DirectoryDB{pool}, nil
+	registerPool(pool)
+	return &DirectoryDB{pool: pool, timer: timer}, nil
 }
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/pkg/errors"
+)
+
+// an arkeo message of a tx which failed. MsgIndex is its top-level message index in the tx, ExecIndex its position
+// among the messages of a MsgExec, 0 when sent directly. Msg holds the message's fields by field number, Provider is the provider
+// pubkey it names if any. Signer signed the message, Grantee executed it through authz MsgExec
+type FailedTx struct {
+	Entity    `json:"-"`
+	TxID      string                 `db:"txid"`
+	MsgIndex  int                    `db:"msg_index"`
+	ExecIndex int                    `db:"exec_index"`
+	Height    int64                  `db:"height"`
+	MsgType   string                 `db:"msg_type"`
+	Msg       map[string]interface{} `db:"msg"`
+	Provider  string                 `db:"provider"`
+	Signer    string                 `db:"signer"`
+	Grantee   string                 `db:"exec_grantee"`
+	FeePayer  string                 `db:"fee_payer"`
+	Memo      string                 `db:"memo"`
+	Code      uint32                 `db:"code"`
+	Codespace string                 `db:"codespace"`
+	Log       string                 `db:"log"`
+}
+
+func (d *DirectoryDB) UpsertFailedTx(tx FailedTx) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlUpsertFailedTx, tx.TxID, tx.MsgIndex, tx.ExecIndex, tx.Height, tx.MsgType, tx.Msg, tx.Provider, tx.Signer,
+		tx.Grantee, tx.FeePayer, tx.Memo, tx.Code, tx.Codespace, tx.Log)
+}
+
+// the most recent failed arkeo messages signed by signer and naming provider, either may be empty to match any
+func (d *DirectoryDB) FindFailedTxs(signer, provider string, limit int) ([]*FailedTx, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	results := make([]*FailedTx, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindFailedTxs, signer, provider, limit); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
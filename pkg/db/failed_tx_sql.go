@@ -0,0 +1,38 @@
+package db
+
+const (
+	sqlUpsertFailedTx = `
+	insert into failed_txs(txid,msg_index,exec_index,height,msg_type,msg,provider,signer,exec_grantee,fee_payer,memo,code,
+		codespace,log)
+	values ($1,$2,$3,$4,$5,$6,nullif($7,''),nullif($8,''),nullif($9,''),nullif($10,''),nullif($11,''),$12,$13,$14)
+	on conflict on constraint failed_txs_txid_msg_key
+	do update set height = $4,
+	              msg_type = $5,
+	              msg = $6,
+	              provider = nullif($7,''),
+	              signer = nullif($8,''),
+	              exec_grantee = nullif($9,''),
+	              fee_payer = nullif($10,''),
+	              memo = nullif($11,''),
+	              code = $12,
+	              codespace = $13,
+	              log = $14,
+	              updated = now()
+	where failed_txs.txid = $1 and failed_txs.msg_index = $2 and failed_txs.exec_index = $3
+	returning id, created, updated
+	`
+	sqlFindFailedTxs = `
+	select f.id, f.created, f.updated, f.txid, f.msg_index, f.exec_index, f.height, f.msg_type, f.msg,
+	       coalesce(f.provider,'') as provider,
+	       coalesce(f.signer,'') as signer,
+	       coalesce(f.exec_grantee,'') as exec_grantee,
+	       coalesce(f.fee_payer,'') as fee_payer,
+	       coalesce(f.memo,'') as memo,
+	       f.code, f.codespace, f.log
+	from failed_txs f
+	where ($1 = '' or f.signer = $1 or f.exec_grantee = $1)
+	  and ($2 = '' or f.provider = $2)
+	order by f.height desc, f.txid, f.msg_index, f.exec_index
+	limit $3
+	`
+)
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestFailedTxs(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, err := New(config)
+	if err != nil {
+		t.Errorf("error getting db: %+v", err)
+		t.FailNow()
+	}
+
+	failed := FailedTx{
+		TxID:      "TESTFAILEDTX",
+		Height:    1,
+		MsgType:   "/arkeo.arkeo.MsgOpenContract",
+		Msg:       map[string]interface{}{"1": "tarkeo1failedsigner"},
+		Provider:  "tarkeopub1failedprovider",
+		Signer:    "tarkeo1failedsigner",
+		Code:      1105,
+		Codespace: "arkeo",
+		Log:       "contract already open",
+	}
+	if _, err = db.UpsertFailedTx(failed); err != nil {
+		t.Errorf("error upserting failed tx: %+v", err)
+		t.FailNow()
+	}
+
+	results, err := db.FindFailedTxs(failed.Signer, failed.Provider, 10)
+	if err != nil {
+		t.Errorf("error finding failed txs: %+v", err)
+		t.FailNow()
+	}
+	if len(results) != 1 || results[0].Log != failed.Log || results[0].Msg["1"] != failed.Signer {
+		t.Errorf("expected the failed tx, got %+v", results)
+	}
+	for _, f := range results {
+		log.Infof("%s %s failed %d: %s", f.TxID, f.MsgType, f.Code, f.Log)
+	}
+
+	results, err = db.FindFailedTxs("", "tarkeopub1notaprovider", 10)
+	if err != nil {
+		t.Errorf("error finding failed txs: %+v", err)
+		t.FailNow()
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no failed txs, got %+v", results)
+	}
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"math"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+)
+
+// SchemaVersion is the latest migration in db/ this build expects, bump it with each new migration
+const SchemaVersion = 47
+
+// check the pool can hand out a working connection
+func (d *DirectoryDB) Ping(ctx context.Context) error {
+	if err := d.pool.Ping(ctx); err != nil {
+		return errors.Wrapf(err, "error pinging db")
+	}
+	return nil
+}
+
+func (d *DirectoryDB) PoolStats() *pgxpool.Stat {
+	return d.pool.Stat()
+}
+
+// find the migration version recorded by tern
+func (d *DirectoryDB) FindSchemaVersion() (int64, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return 0, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	version := struct {
+		Version int64 `db:"version"`
+	}{Version: math.MinInt64} // used to designate not found
+	if err = selectOne(conn, sqlFindSchemaVersion, &version); err != nil {
+		return 0, errors.Wrapf(err, "error selecting")
+	}
+	if version.Version == math.MinInt64 {
+		return 0, errors.New("no schema version recorded")
+	}
+	return version.Version, nil
+}
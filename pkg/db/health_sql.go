@@ -0,0 +1,3 @@
+package db
+
+var sqlFindSchemaVersion = `select version from schema_version limit 1`
@@ -0,0 +1,16 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	migrations, err := filepath.Glob("../../db/[0-9][0-9][0-9]_*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != SchemaVersion {
+		t.Errorf("SchemaVersion is %d but db/ has %d migrations", SchemaVersion, len(migrations))
+	}
+}
@@ -1,8 +1,12 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
 
 	"github.com/pkg/errors"
 )
@@ -10,6 +14,20 @@ import (
 type IndexerStatus struct {
 	ID     int64  `db:"id"`
 	Height uint64 `db:"height"`
+	// latest height reported by the chain
+	ChainTip        *int64     `db:"chain_tip"`
+	ChainTipUpdated *time.Time `db:"chain_tip_updated"`
+	// progress of the current, or if finished the last, gap fill run
+	GapFillBlocks   int64      `db:"gap_fill_blocks"`
+	GapFillDone     int64      `db:"gap_fill_done"`
+	GapFillStarted  *time.Time `db:"gap_fill_started"`
+	GapFillFinished *time.Time `db:"gap_fill_finished"`
+}
+
+// number of indexed rows per event type
+type EventCount struct {
+	EventType string `db:"event_type"`
+	Count     int64  `db:"count"`
 }
 
 func (d *DirectoryDB) UpsertIndexerStatus(indexerStatus *IndexerStatus) (*Entity, error) {
@@ -58,3 +76,90 @@ func (d *DirectoryDB) FindIndexerStatus(id int64) (*IndexerStatus, error) {
 	}
 	return &indexerStatus, nil
 }
+
+// find the status of the lowest indexer id, as the views do with a single indexer
+func (d *DirectoryDB) FindLatestIndexerStatus() (*IndexerStatus, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	indexerStatus := IndexerStatus{Height: math.MaxUint64} // used to designate not found... need a better way!
+	if err = selectOne(conn, sqlFindLatestIndexerStatus, &indexerStatus); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if indexerStatus.Height == math.MaxUint64 {
+		return nil, nil
+	}
+	return &indexerStatus, nil
+}
+
+// record the height blocks are indexed through, the one before the first gap, and the chain tip. the indexed height
+// moves back when a gap opens below it, the chain tip never moves backwards
+func (d *DirectoryDB) UpdateIndexerTip(id int64, height int64, chainTip int64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlUpdateIndexerTip, id, height, chainTip)
+}
+
+// record the chain tip alone, leaving the indexed height to UpdateIndexerTip
+func (d *DirectoryDB) UpdateChainTip(id int64, chainTip int64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlUpdateChainTip, id, chainTip)
+}
+
+// record the start of a gap fill run over blocks blocks
+func (d *DirectoryDB) StartGapFill(id int64, blocks int64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlStartGapFill, id, blocks)
+}
+
+// add done blocks to the progress of the current gap fill run
+func (d *DirectoryDB) AddGapFillProgress(id int64, done int64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return update(conn, sqlAddGapFillProgress, id, done)
+}
+
+func (d *DirectoryDB) FinishGapFill(id int64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return update(conn, sqlFinishGapFill, id)
+}
+
+func (d *DirectoryDB) FindEventCounts() ([]*EventCount, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	results := make([]*EventCount, 0, 8)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindEventCounts); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
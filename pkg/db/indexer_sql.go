@@ -9,5 +9,73 @@ var (
 		returning id, created, updated
 	`
 	sqlUpdateIndexerStatus = `update indexer_status set height = $2, updated = now() where id = $1 returning id, created, updated`
-	sqlFindIndexerStatus   = `select id,height from indexer_status where id = $1`
+	indexerStatusCols      = `
+		id,
+		height,
+		chain_tip,
+		chain_tip_updated,
+		gap_fill_blocks,
+		gap_fill_done,
+		gap_fill_started,
+		gap_fill_finished
+	`
+	sqlFindIndexerStatus       = `select ` + indexerStatusCols + ` from indexer_status where id = $1`
+	sqlFindLatestIndexerStatus = `select ` + indexerStatusCols + ` from indexer_status order by id limit 1`
+	sqlUpdateIndexerTip        = `
+		insert into indexer_status(id,height,chain_tip,chain_tip_updated) values ($1,$2,$3,now())
+		on conflict on constraint indexer_status_pk do update
+		set height = $2,
+		    chain_tip = greatest(indexer_status.chain_tip, $3),
+		    chain_tip_updated = now(),
+		    updated = now()
+		where indexer_status.id = $1
+		returning id, created, updated
+	`
+	sqlUpdateChainTip = `
+		insert into indexer_status(id,height,chain_tip,chain_tip_updated) values ($1,0,$2,now())
+		on conflict on constraint indexer_status_pk do update
+		set chain_tip = greatest(indexer_status.chain_tip, $2),
+		    chain_tip_updated = now(),
+		    updated = now()
+		where indexer_status.id = $1
+		returning id, created, updated
+	`
+	sqlStartGapFill = `
+		insert into indexer_status(id,height,gap_fill_blocks,gap_fill_done,gap_fill_started) values ($1,0,$2,0,now())
+		on conflict on constraint indexer_status_pk do update
+		set gap_fill_blocks = $2,
+		    gap_fill_done = 0,
+		    gap_fill_started = now(),
+		    gap_fill_finished = null,
+		    updated = now()
+		where indexer_status.id = $1
+		returning id, created, updated
+	`
+	sqlAddGapFillProgress = `
+		update indexer_status
+		set gap_fill_done = gap_fill_done + $2,
+		    updated = now()
+		where id = $1
+		returning id, created, updated
+	`
+	sqlFinishGapFill = `
+		update indexer_status
+		set gap_fill_finished = now(),
+		    updated = now()
+		where id = $1
+		returning id, created, updated
+	`
+	sqlFindEventCounts = `
+		select 'bond_provider' as event_type, count(1) as count from provider_bond_events
+		union all
+		select 'mod_provider', count(1) from provider_mod_events
+		union all
+		select 'open_contract', count(1) from open_contract_events
+		union all
+		select 'close_contract', count(1) from close_contract_events
+		union all
+		select 'contract_settlement', count(1) from contract_settlement_events
+		union all
+		select 'validator_payout', count(1) from validator_payout_events
+	`
 )
@@ -80,3 +80,34 @@ func TestFindIndexerStatus(t *testing.T) {
 		t.Errorf("expected nil but got %v", indexerStatus)
 	}
 }
+
+func TestUpdateIndexerTip(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, err := New(config)
+	if err != nil {
+		t.Errorf("error getting db: %+v", err)
+	}
+
+	var id int64 = 555557
+	if _, err = db.UpdateIndexerTip(id, 100, 120); err != nil {
+		t.Fatalf("error updating indexer tip: %+v", err)
+	}
+	// a gap opening below the indexed height moves it back, realtime blocks only move the chain tip
+	if _, err = db.UpdateIndexerTip(id, 80, 110); err != nil {
+		t.Fatalf("error updating indexer tip: %+v", err)
+	}
+	if _, err = db.UpdateChainTip(id, 130); err != nil {
+		t.Fatalf("error updating chain tip: %+v", err)
+	}
+	status, err := db.FindIndexerStatus(id)
+	if err != nil || status == nil {
+		t.Fatalf("error finding indexer status: %+v", err)
+	}
+	if status.Height != 80 || status.ChainTip == nil || *status.ChainTip != 130 {
+		t.Errorf("expected height 80 and chain tip 130, got %d and %v", status.Height, status.ChainTip)
+	}
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/tracing"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "directory",
+	Name:      "db_query_duration_seconds",
+	Help:      "Time a DirectoryDB method holds its connection, by method.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"method"})
+
+// connection checkouts in flight, keyed by the underlying conn handed back to AfterRelease
+type checkout struct {
+	method string
+	start  time.Time
+	span   trace.Span
+}
+
+type queryTimer struct {
+	checkouts sync.Map
+}
+
+// begin timing and tracing a checkout for the DirectoryDB method skip frames above the caller, the span covers
+// waiting for a connection as well as the queries run on it
+func (t *queryTimer) begin(ctx context.Context, skip int) (context.Context, *checkout) {
+	method := "unknown"
+	if pc, _, _, ok := runtime.Caller(skip + 1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			method = fn.Name()[strings.LastIndex(fn.Name(), ".")+1:]
+		}
+	}
+	ctx, span := tracing.Start(ctx, "db."+method,
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationKey.String(method),
+	)
+	return ctx, &checkout{method: method, start: time.Now(), span: span}
+}
+
+// acquired hands the checkout to the release hook, or ends it if the connection could not be obtained
+func (t *queryTimer) acquired(c *checkout, conn *pgxpool.Conn, err error) {
+	if err != nil {
+		tracing.End(c.span, err)
+		return
+	}
+	t.checkouts.Store(conn.Conn(), c)
+}
+
+// pool AfterRelease hook, always keeps the connection
+func (t *queryTimer) release(conn *pgx.Conn) bool {
+	if v, ok := t.checkouts.LoadAndDelete(conn); ok {
+		c := v.(*checkout)
+		queryDuration.WithLabelValues(c.method).Observe(time.Since(c.start).Seconds())
+		c.span.End()
+	}
+	return true
+}
+
+// poolCollector reports pool usage at scrape time
+type poolCollector struct {
+	pool                                      *pgxpool.Pool
+	total, acquired, idle, max, acquireWaited *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("directory", "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:          pool,
+		total:         desc("total_conns", "Connections open in the pool."),
+		acquired:      desc("acquired_conns", "Connections currently checked out."),
+		idle:          desc("idle_conns", "Idle connections in the pool."),
+		max:           desc("max_conns", "Maximum pool size."),
+		acquireWaited: desc("empty_acquire_total", "Acquires that waited for a connection because the pool was empty."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.total
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.max
+	ch <- c.acquireWaited
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWaited, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+}
+
+// register the pool's collector, only the first pool of a process is reported
+func registerPool(pool *pgxpool.Pool) {
+	if err := prometheus.Register(newPoolCollector(pool)); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			log.Warnf("error registering db pool metrics: %+v", err)
+		}
+	}
+}
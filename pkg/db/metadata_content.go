@@ -0,0 +1,53 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// hash of the document stored for a provider's metadata nonce and whether a re-fetch returned different content
+type ProviderMetadataContent struct {
+	Entity        `json:"-"`
+	ProviderID    int64      `db:"provider_id"`
+	Nonce         uint64     `db:"nonce"`
+	ContentHash   *string    `db:"content_hash"`
+	ContentDrift  bool       `db:"content_drift"`
+	DriftHash     *string    `db:"drift_hash"`
+	DriftDetected *time.Time `db:"drift_detected"`
+}
+
+// find the stored content for the provider's metadata nonce, nil if the nonce hasn't been fetched
+func (d *DirectoryDB) FindProviderMetadataContent(providerID int64, nonce uint64) (*ProviderMetadataContent, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	content := ProviderMetadataContent{}
+	if err = selectOne(conn, sqlFindProviderMetadataContent, &content, providerID, nonce); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if content.ID == 0 {
+		return nil, nil
+	}
+	return &content, nil
+}
+
+// flag that a re-fetch of nonce returned content hashing to driftHash. the stored document is left as is
+func (d *DirectoryDB) FlagProviderMetadataDrift(providerID int64, nonce uint64, driftHash string) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	entity, err := update(conn, sqlFlagProviderMetadataDrift, providerID, nonce, driftHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return entity, err
+}
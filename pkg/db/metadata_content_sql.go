@@ -0,0 +1,21 @@
+package db
+
+const (
+	sqlFindProviderMetadataContent = `
+	select id, created, updated, provider_id, nonce, content_hash, content_drift, drift_hash, drift_detected
+	from provider_metadata
+	where provider_id = $1
+	  and nonce = $2
+	`
+	// drift_detected keeps the first detection until the content matches again
+	sqlFlagProviderMetadataDrift = `
+	update provider_metadata
+	set content_drift = true,
+	    drift_hash = $3,
+	    drift_detected = coalesce(drift_detected, now()),
+	    updated = now()
+	where provider_id = $1
+	  and nonce = $2
+	returning id, created, updated
+	`
+)
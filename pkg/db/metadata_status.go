@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// fetch state of the metadata document of a provider, one row per provider
+type ProviderMetadataStatus struct {
+	Entity        `json:"-"`
+	ProviderID    int64                     `db:"provider_id" json:"-"`
+	Pubkey        string                    `db:"pubkey"`
+	Chain         string                    `db:"chain"`
+	MetadataURI   string                    `db:"metadata_uri"`
+	MetadataNonce uint64                    `db:"metadata_nonce"`
+	Status        types.MetadataFetchStatus `db:"status"`
+	Attempts      int                       `db:"attempts"`
+	LastError     string                    `db:"last_error"`
+	LastAttempt   *time.Time                `db:"last_attempt"`
+	LastSuccess   *time.Time                `db:"last_success"`
+	NextAttempt   time.Time                 `db:"next_attempt"`
+	// validation of the stored document for MetadataNonce, nil until fetched
+	SchemaVersion      *string  `db:"schema_version"`
+	ValidationWarnings []string `db:"validation_warnings"`
+	ValidationErrors   []string `db:"validation_errors"`
+	IsValid            *bool    `db:"is_valid"`
+	Verified           *bool    `db:"verified"`
+	// sha256 of the stored document, drift is flagged when a re-fetch of the same nonce hashes differently
+	ContentHash   *string    `db:"content_hash"`
+	ContentDrift  bool       `db:"content_drift"`
+	DriftHash     *string    `db:"drift_hash"`
+	DriftDetected *time.Time `db:"drift_detected"`
+}
+
+// queue a (re)fetch of the provider's metadata, replacing any previously queued uri/nonce
+func (d *DirectoryDB) QueueMetadataFetch(providerID int64, metadataURI string, metadataNonce uint64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlQueueMetadataFetch, providerID, metadataURI, metadataNonce, types.MetadataFetchStatusPending)
+}
+
+// find up to limit metadata fetches whose next attempt is due, oldest first
+func (d *DirectoryDB) FindDueMetadataFetches(limit int) ([]*ProviderMetadataStatus, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	results := make([]*ProviderMetadataStatus, 0, limit)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindDueMetadataFetches, limit); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
+
+func (d *DirectoryDB) FindMetadataStatus(pubkey string, chain string) (*ProviderMetadataStatus, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	status := ProviderMetadataStatus{}
+	if err = selectOne(conn, sqlFindMetadataStatus, &status, pubkey, chain); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if status.ID == 0 {
+		return nil, nil
+	}
+	return &status, nil
+}
+
+// record a successful fetch of nonce. returns nil if a newer nonce was queued in the meantime
+func (d *DirectoryDB) MarkMetadataFetchSuccess(id int64, nonce uint64, nextAttempt time.Time) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	entity, err := update(conn, sqlMarkMetadataFetchSuccess, id, nonce, types.MetadataFetchStatusFetched, nextAttempt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return entity, err
+}
+
+// record a failed fetch of nonce. returns nil if a newer nonce was queued in the meantime
+func (d *DirectoryDB) MarkMetadataFetchFailure(id int64, nonce uint64, attempts int, fetchErr string, nextAttempt time.Time) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	entity, err := update(conn, sqlMarkMetadataFetchFailure, id, nonce, types.MetadataFetchStatusFailed, attempts, fetchErr, nextAttempt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return entity, err
+}
@@ -0,0 +1,85 @@
+package db
+
+const (
+	metadataStatusCols = `
+	s.id,
+	s.created,
+	s.updated,
+	s.provider_id,
+	p.pubkey,
+	p.chain,
+	s.metadata_uri,
+	s.metadata_nonce,
+	s.status,
+	s.attempts,
+	coalesce(s.last_error,'') as last_error,
+	s.last_attempt,
+	s.last_success,
+	s.next_attempt,
+	m.schema_version,
+	coalesce(m.validation_warnings,'{}') as validation_warnings,
+	coalesce(m.validation_errors,'{}') as validation_errors,
+	m.is_valid,
+	m.verified,
+	m.content_hash,
+	coalesce(m.content_drift,false) as content_drift,
+	m.drift_hash,
+	m.drift_detected
+	`
+	metadataStatusFrom = `
+	from provider_metadata_status s
+		join providers p on p.id = s.provider_id
+		left join provider_metadata m on m.provider_id = s.provider_id and m.nonce = s.metadata_nonce
+	`
+)
+
+const (
+	sqlQueueMetadataFetch = `
+	insert into provider_metadata_status(provider_id,metadata_uri,metadata_nonce,status)
+	values ($1,$2,$3,$4)
+	on conflict on constraint prov_meta_status_prov_uniq
+	do update set metadata_uri = $2,
+	              metadata_nonce = $3,
+	              status = $4,
+	              attempts = 0,
+	              last_error = null,
+	              next_attempt = now(),
+	              updated = now()
+	where provider_metadata_status.provider_id = $1
+	returning id, created, updated
+	`
+	sqlFindDueMetadataFetches = `select ` + metadataStatusCols + metadataStatusFrom + `
+	where s.next_attempt <= now()
+	order by s.next_attempt
+	limit $1
+	`
+	sqlFindMetadataStatus = `select ` + metadataStatusCols + metadataStatusFrom + `
+	where p.pubkey = $1 and p.chain = $2
+	`
+	// the nonce guard keeps a slow fetch from overwriting a newer queued nonce
+	sqlMarkMetadataFetchSuccess = `
+	update provider_metadata_status
+	set status = $3,
+	    attempts = 0,
+	    last_error = null,
+	    last_attempt = now(),
+	    last_success = now(),
+	    next_attempt = $4,
+	    updated = now()
+	where id = $1
+	  and metadata_nonce = $2
+	returning id, created, updated
+	`
+	sqlMarkMetadataFetchFailure = `
+	update provider_metadata_status
+	set status = $3,
+	    attempts = $4,
+	    last_error = $5,
+	    last_attempt = now(),
+	    next_attempt = $6,
+	    updated = now()
+	where id = $1
+	  and metadata_nonce = $2
+	returning id, created, updated
+	`
+)
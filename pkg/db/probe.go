@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/pkg/errors"
+)
+
+// a provider sentinel endpoint to probe, taken from the provider's current metadata
+type ProbeTarget struct {
+	ProviderID int64  `db:"provider_id"`
+	Pubkey     string `db:"pubkey"`
+	Chain      string `db:"chain"`
+	ProxyHost  string `db:"proxy_host"`
+	Port       string `db:"port"`
+}
+
+// outcome of a single probe of a provider's sentinel
+type ProviderProbe struct {
+	ProviderID int64
+	Endpoint   string
+	Success    bool
+	StatusCode int
+	Latency    time.Duration
+	Error      string
+	ProbedAt   time.Time
+}
+
+// find providers whose current, valid metadata declares a proxy host
+func (d *DirectoryDB) FindProbeTargets() ([]*ProbeTarget, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	results := make([]*ProbeTarget, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindProbeTargets); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
+
+func (d *DirectoryDB) InsertProviderProbe(probe ProviderProbe) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	var statusCode *int
+	if probe.StatusCode != 0 {
+		statusCode = &probe.StatusCode
+	}
+	var probeErr *string
+	if probe.Error != "" {
+		probeErr = &probe.Error
+	}
+	return insert(conn, sqlInsertProviderProbe, probe.ProviderID, probe.Endpoint, probe.Success, statusCode,
+		probe.Latency.Milliseconds(), probeErr, probe.ProbedAt)
+}
+
+// delete probes recorded before cutoff, returning the number deleted
+func (d *DirectoryDB) DeleteProviderProbesBefore(cutoff time.Time) (int64, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return 0, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	tag, err := conn.Exec(context.Background(), sqlDeleteProviderProbesBefore, cutoff)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error deleting probes")
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,24 @@
+package db
+
+const (
+	sqlFindProbeTargets = `
+	select p.id as provider_id,
+	       p.pubkey,
+	       p.chain,
+	       m.proxy_host,
+	       coalesce(m.port,'') as port
+	from providers p
+		join provider_metadata m on m.provider_id = p.id and m.nonce = p.metadata_nonce
+	where coalesce(m.proxy_host,'') != ''
+	  and m.is_valid
+	order by p.id
+	`
+	sqlInsertProviderProbe = `
+	insert into provider_probes(provider_id,endpoint,success,status_code,latency_ms,error,probed_at)
+	values ($1,$2,$3,$4,$5,$6,$7)
+	returning id, created, updated
+	`
+	sqlDeleteProviderProbesBefore = `
+	delete from provider_probes where probed_at < $1
+	`
+)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/arkeonetwork/directory/pkg/sentinel"
 	"github.com/arkeonetwork/directory/pkg/types"
@@ -16,16 +17,34 @@ import (
 type ArkeoProvider struct {
 	Entity `json:"-"`
 	Pubkey string `db:"pubkey"`
-	Chain  string `db:"chain"`
-	// this is a DECIMAL type in the db
-	Bond                string               `db:"bond"`
+	// account address derived from Pubkey, empty until the indexer has recorded it
+	Address             string               `db:"address"`
+	Chain               string               `db:"chain"`
+	Bond                types.Amount         `db:"bond"`
 	MetadataURI         string               `db:"metadata_uri"`
 	MetadataNonce       uint64               `db:"metadata_nonce"`
 	Status              types.ProviderStatus `db:"status,text"`
 	MinContractDuration int64                `db:"min_contract_duration"`
 	MaxContractDuration int64                `db:"max_contract_duration"`
-	SubscriptionRate    int64                `db:"subscription_rate"`
-	PayAsYouGoRate      int64                `db:"paygo_rate"`
+	SubscriptionRate    types.Amount         `db:"subscription_rate"`
+	PayAsYouGoRate      types.Amount         `db:"paygo_rate"`
+	// the metadata document for MetadataNonce carries a valid detached signature by the provider's key
+	MetadataVerified bool `db:"metadata_verified"`
+	// observed by the prober over the last 24h, nil until the provider's sentinel has been probed.
+	// Uptime is the fraction of successful probes, latencies are in milliseconds
+	Uptime         *float64   `db:"uptime"`
+	LatencyP50     *float64   `db:"latency_p50"`
+	LatencyP90     *float64   `db:"latency_p90"`
+	LatencyP99     *float64   `db:"latency_p99"`
+	LastProbed     *time.Time `db:"last_probed"`
+	LastProbeError *string    `db:"last_probe_error"`
+	// composite reputation score (0-100), nil until first computed
+	ReputationScore *float64 `db:"reputation_score"`
+	// paid out to the validator operated with the provider's key
+	ValidatorPaid types.Amount `db:"validator_paid"`
+	// blocks since the provider first bonded, AgeSeconds is estimated from the average block time
+	Age        int64    `db:"age"`
+	AgeSeconds *float64 `db:"-"`
 }
 
 func (d *DirectoryDB) InsertProvider(provider *ArkeoProvider) (*Entity, error) {
@@ -87,6 +106,7 @@ const provSearchCols = `
 	p.id,
 	p.created,
 	p.pubkey,
+	coalesce((select pa.address from pubkey_addresses pa where pa.pubkey = p.pubkey),'') as address,
 	p.chain, 
 	coalesce(p.status,'Offline') as status,
 	coalesce(p.metadata_uri,'') as metadata_uri,
@@ -95,7 +115,17 @@ const provSearchCols = `
 	coalesce(p.paygo_rate,0) as paygo_rate,
 	coalesce(p.min_contract_duration,0) as min_contract_duration,
 	coalesce(p.max_contract_duration,0) as max_contract_duration,
-	coalesce(p.bond,0) as bond
+	coalesce(p.bond,0) as bond,
+	coalesce(provider_metadata.verified,false) as metadata_verified,
+	ps.uptime,
+	ps.latency_p50,
+	ps.latency_p90,
+	ps.latency_p99,
+	ps.last_probed,
+	ps.last_error as last_probe_error,
+	rep.score as reputation_score,
+	` + sqlProviderValidatorPaid + ` as validator_paid,
+	coalesce(p.age,0) as age
 `
 
 func (d *DirectoryDB) SearchProviders(criteria types.ProviderSearchParams) ([]*ArkeoProvider, error) {
@@ -117,8 +147,13 @@ func (d *DirectoryDB) SearchProviders(criteria types.ProviderSearchParams) ([]*A
 	if criteria.Chain != "" {
 		sb = sb.Where(sb.Equal("p.chain", criteria.Chain))
 	}
-	if criteria.IsMaxDistanceSet || criteria.IsMinFreeRateLimitSet || criteria.IsMinPaygoRateLimitSet || criteria.IsMinSubscribeRateLimitSet {
-		sb = sb.JoinWithOption(sqlbuilder.LeftJoin, "provider_metadata", "p.id = provider_metadata.provider_id and p.metadata_nonce = provider_metadata.nonce")
+	sb = sb.JoinWithOption(sqlbuilder.LeftJoin, "provider_metadata", "p.id = provider_metadata.provider_id and p.metadata_nonce = provider_metadata.nonce")
+	// providers without fetched metadata remain searchable, those whose metadata failed hard checks don't
+	sb = sb.Where("coalesce(provider_metadata.is_valid, true)")
+	sb = sb.JoinWithOption(sqlbuilder.LeftJoin, "provider_probe_stats_v ps", "p.id = ps.provider_id")
+	sb = sb.JoinWithOption(sqlbuilder.LeftJoin, "provider_reputation rep", "p.id = rep.provider_id")
+	if criteria.IsVerifiedSet {
+		sb = sb.Where(sb.Equal("coalesce(provider_metadata.verified, false)", criteria.Verified))
 	}
 	if criteria.IsMaxDistanceSet {
 		// note psql using long,lat instead of the normal lat,long per https://www.postgresql.org/docs/current/earthdistance.html
@@ -141,7 +176,13 @@ func (d *DirectoryDB) SearchProviders(criteria types.ProviderSearchParams) ([]*A
 		sb = sb.Where(sb.GE("p.contract_count", criteria.MinOpenContracts))
 	}
 	if criteria.IsMinValidatorPaymentsSet {
-		sb = sb.Where(sb.GE("p.total_paid", criteria.MinValidatorPayments))
+		sb = sb.Where(sb.GE(sqlProviderValidatorPaid, criteria.MinValidatorPayments))
+	}
+	if criteria.IsMinUptimeSet {
+		sb = sb.Where(sb.GE("ps.uptime", criteria.MinUptime))
+	}
+	if criteria.IsMaxLatencySet {
+		sb = sb.Where(sb.LE("ps.latency_p50", criteria.MaxLatency))
 	}
 
 	// Sort
@@ -154,6 +195,12 @@ func (d *DirectoryDB) SearchProviders(criteria types.ProviderSearchParams) ([]*A
 		sb = sb.OrderBy("p.contract_count").Desc()
 	case types.ProviderSortKeyAmountPaid:
 		sb = sb.OrderBy("p.total_paid").Desc()
+	case types.ProviderSortKeyUptime:
+		sb = sb.OrderBy("ps.uptime desc nulls last", "ps.latency_p50 asc nulls last")
+	case types.ProviderSortKeyLatency:
+		sb = sb.OrderBy("ps.latency_p50 asc nulls last")
+	case types.ProviderSortKeyReputation:
+		sb = sb.OrderBy("rep.score desc nulls last")
 	default:
 		return nil, fmt.Errorf("not a valid sortKey %s", criteria.SortKey)
 	}
@@ -169,21 +216,11 @@ func (d *DirectoryDB) SearchProviders(criteria types.ProviderSearchParams) ([]*A
 	return providers, nil
 }
 
-func (d *DirectoryDB) UpsertValidatorPayoutEvent(evt types.ValidatorPayoutEvent) (*Entity, error) {
-	conn, err := d.getConnection()
-	defer conn.Release()
-	if err != nil {
-		return nil, errors.Wrapf(err, "error obtaining db connection")
-	}
-
-	return upsert(conn, sqlUpsertValidatorPayoutEvent, evt.Validator, evt.Height, evt.Paid)
-}
-
 func (d *DirectoryDB) InsertBondProviderEvent(providerID int64, evt types.BondProviderEvent) (*Entity, error) {
-	if evt.BondAbsolute == "" {
+	if !evt.BondAbsolute.IsSet() {
 		return nil, fmt.Errorf("nil BondAbsolute")
 	}
-	if evt.BondRelative == "" {
+	if !evt.BondRelative.IsSet() {
 		return nil, fmt.Errorf("nil BondRelative")
 	}
 	conn, err := d.getConnection()
@@ -192,7 +229,8 @@ func (d *DirectoryDB) InsertBondProviderEvent(providerID int64, evt types.BondPr
 		return nil, errors.Wrapf(err, "error obtaining db connection")
 	}
 
-	return insert(conn, sqlInsertBondProviderEvent, providerID, evt.Height, evt.TxID, evt.BondRelative, evt.BondAbsolute)
+	return insert(conn, sqlInsertBondProviderEvent, providerID, evt.Height, evt.TxID, evt.BondRelative, evt.BondAbsolute,
+		evt.MsgIndex, evt.EventIndex, evt.Signer, evt.Grantee, evt.FeePayer, evt.Memo)
 }
 
 func (d *DirectoryDB) InsertModProviderEvent(providerID int64, evt types.ModProviderEvent) (*Entity, error) {
@@ -203,10 +241,14 @@ func (d *DirectoryDB) InsertModProviderEvent(providerID int64, evt types.ModProv
 	}
 
 	return insert(conn, sqlInsertModProviderEvent, providerID, evt.Height, evt.TxID, evt.MetadataURI, evt.MetadataNonce, evt.Status,
-		evt.MinContractDuration, evt.MaxContractDuration, evt.SubscriptionRate, evt.PayAsYouGoRate)
+		evt.MinContractDuration, evt.MaxContractDuration, evt.SubscriptionRate, evt.PayAsYouGoRate, evt.MsgIndex, evt.EventIndex,
+		evt.Signer, evt.Grantee, evt.FeePayer, evt.Memo)
 }
 
-func (d *DirectoryDB) UpsertProviderMetadata(providerID int64, data sentinel.Metadata) (*Entity, error) {
+// upsert the parsed metadata along with the raw document it was parsed from. a nonce's document and hash are
+// written once, callers compare hashes before upserting a re-fetch (see FindProviderMetadataContent).
+// verified records whether the document's detached signature checked out on this fetch
+func (d *DirectoryDB) UpsertProviderMetadata(providerID int64, data sentinel.Metadata, validation types.MetadataValidation, raw []byte, contentHash string, verified bool) (*Entity, error) {
 	conn, err := d.getConnection()
 	defer conn.Release()
 	if err != nil {
@@ -227,5 +269,15 @@ func (d *DirectoryDB) UpsertProviderMetadata(providerID int64, data sentinel.Met
 	// TODO - always insert instead of upsert, fail on dupe (or read and fail on exists). are there any restrictions on version string?
 	return insert(conn, sqlUpsertProviderMetadata, providerID, c.Nonce, c.Moniker, c.Website, c.Description, location,
 		c.Port, c.ProxyHost, c.SourceChain, c.EventStreamHost, c.ClaimStoreLocation, c.FreeTierRateLimit, c.FreeTierRateLimitDuration,
-		c.SubTierRateLimit, c.SubTierRateLimitDuration, c.AsGoTierRateLimit, c.AsGoTierRateLimitDuration)
+		c.SubTierRateLimit, c.SubTierRateLimitDuration, c.AsGoTierRateLimit, c.AsGoTierRateLimitDuration,
+		data.Version, validation.SchemaVersion, nonNilStrings(validation.Warnings), nonNilStrings(validation.Errors), validation.IsValid(),
+		raw, contentHash, verified)
+}
+
+// nil slices are sent as NULL, which the not null array columns reject
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
 }
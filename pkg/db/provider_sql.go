@@ -25,6 +25,7 @@ var (
 			created,
 			updated,
 			pubkey,
+			coalesce((select pa.address from pubkey_addresses pa where pa.pubkey = p.pubkey),'') as address,
 			chain,
 			coalesce(bond,0) as bond,
 			coalesce(metadata_uri,'') as metadata_uri,
@@ -33,44 +34,72 @@ var (
 			coalesce(min_contract_duration,-1) as min_contract_duration,
 			coalesce(max_contract_duration,-1) as max_contract_duration,
 			coalesce(subscription_rate,-1) as subscription_rate,
-			coalesce(paygo_rate,-1) as paygo_rate
+			coalesce(paygo_rate,-1) as paygo_rate,
+			coalesce(m.verified,false) as metadata_verified,
+			ps.uptime,
+			ps.latency_p50,
+			ps.latency_p90,
+			ps.latency_p99,
+			ps.last_probed,
+			ps.last_error as last_probe_error,
+			rep.score as reputation_score,
+			` + sqlProviderValidatorPaid + ` as validator_paid,
+			coalesce((select pv.age from providers_v pv where pv.id = p.id),0) as age
 		from providers p
+			left join provider_metadata m on m.provider_id = p.id and m.nonce = p.metadata_nonce
+			left join provider_probe_stats_v ps on ps.provider_id = p.id
+			left join provider_reputation rep on rep.provider_id = p.id
 		where p.pubkey = $1
 		  and p.chain = $2
 	`
 	sqlInsertBondProviderEvent = `
-		insert into provider_bond_events(provider_id,height,txid,bond_rel,bond_abs)
-		values ($1,$2,$3,$4,$5)
-		on conflict on constraint provider_bond_events_txid_unq
-		do update set updated = now()
+		insert into provider_bond_events(provider_id,height,txid,bond_rel,bond_abs,msg_index,event_index,signer,exec_grantee,fee_payer,memo)
+		values ($1,$2,$3,$4,$5,$6,$7,nullif($8,''),nullif($9,''),nullif($10,''),nullif($11,''))
+		on conflict on constraint provider_bond_events_txid_msg_evt_key
+		do update set signer = coalesce(provider_bond_events.signer, excluded.signer),
+		              exec_grantee = coalesce(provider_bond_events.exec_grantee, excluded.exec_grantee),
+		              fee_payer = coalesce(provider_bond_events.fee_payer, excluded.fee_payer),
+		              memo = coalesce(provider_bond_events.memo, excluded.memo),
+		              updated = now()
 		where provider_bond_events.txid = $3
+		  and provider_bond_events.msg_index = $6
+		  and provider_bond_events.event_index = $7
 		returning id, created, updated
 	`
 	sqlInsertModProviderEvent = `
-		insert into provider_mod_events(provider_id,height,txid,metadata_uri,metadata_nonce,status,min_contract_duration,max_contract_duration,subscription_rate,paygo_rate)
-		values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
-		on conflict on constraint provider_mod_events_txid_unq
-		do update set updated = now()
+		insert into provider_mod_events(provider_id,height,txid,metadata_uri,metadata_nonce,status,min_contract_duration,max_contract_duration,subscription_rate,paygo_rate,
+			msg_index,event_index,signer,exec_grantee,fee_payer,memo)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,nullif($13,''),nullif($14,''),nullif($15,''),nullif($16,''))
+		on conflict on constraint provider_mod_events_txid_msg_evt_key
+		do update set signer = coalesce(provider_mod_events.signer, excluded.signer),
+		              exec_grantee = coalesce(provider_mod_events.exec_grantee, excluded.exec_grantee),
+		              fee_payer = coalesce(provider_mod_events.fee_payer, excluded.fee_payer),
+		              memo = coalesce(provider_mod_events.memo, excluded.memo),
+		              updated = now()
 		where provider_mod_events.txid = $3
+		  and provider_mod_events.msg_index = $11
+		  and provider_mod_events.event_index = $12
 		returning id, created, updated
 	`
 	sqlUpsertProviderMetadata = `
 		insert into provider_metadata(provider_id,nonce,moniker,website,description,location,port,proxy_host,source_chain,event_stream_host,claim_store_location,
-			free_rate_limit,free_rate_limit_duration,subscribe_rate_limit,subscribe_rate_limit_duration,paygo_rate_limit,paygo_rate_limit_duration)
-		values ($1,$2,$3,$4,$5,CAST(NULLIF($6, '') AS point),$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)
+			free_rate_limit,free_rate_limit_duration,subscribe_rate_limit,subscribe_rate_limit_duration,paygo_rate_limit,paygo_rate_limit_duration,
+			version,schema_version,validation_warnings,validation_errors,is_valid,raw_document,content_hash,verified)
+		values ($1,$2,$3,$4,$5,CAST(NULLIF($6, '') AS point),$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25)
 		on conflict on constraint prov_metanonce_uniq
-		do update set updated = now()
+		do update set schema_version = $19,
+		              validation_warnings = $20,
+		              validation_errors = $21,
+		              is_valid = $22,
+		              raw_document = coalesce(provider_metadata.raw_document, $23),
+		              content_hash = coalesce(provider_metadata.content_hash, $24),
+		              verified = $25,
+		              content_drift = false,
+		              drift_hash = null,
+		              drift_detected = null,
+		              updated = now()
 		where provider_metadata.provider_id = $1
 		  and provider_metadata.nonce = $2
 		returning id, created, updated
 	`
-	sqlUpsertValidatorPayoutEvent = `
-	insert into validator_payout_events(validator,height,paid)
-	values ($1,$2,$3)
-	on conflict on constraint validator_payout_evts_validator_height_key
-	do update set updated = now()
-	where validator_payout_events.validator = $1
-	  and validator_payout_events.height = $2
-	returning id, created, updated
-	`
 )
@@ -23,7 +23,7 @@ func TestInsertProvider(t *testing.T) {
 	entity, err := db.InsertProvider(&ArkeoProvider{
 		Pubkey: uuid.NewString(),
 		Chain:  "btc-mainnet-fullnode",
-		Bond:   "1234567890",
+		Bond:   types.NewAmount(1234567890, types.DefaultDenom),
 	})
 	if err != nil {
 		t.Errorf("error inserting provider: %+v", err)
@@ -72,7 +72,7 @@ func TestUpsertProviderMetadata(t *testing.T) {
 	if err != nil {
 		t.Errorf("error getting db: %+v", err)
 	}
-	if _, err = db.UpsertProviderMetadata(1, sentinel.Metadata{Version: "0.0.6t", Configuration: sentinel.Configuration{Moniker: "UnitTestOper", AsGoTierRateLimitDuration: time.Hour * 24 * 365 * 10, Location: "50.1535,-19.165"}}); err != nil {
+	if _, err = db.UpsertProviderMetadata(1, sentinel.Metadata{Version: "0.0.6t", Configuration: sentinel.Configuration{Moniker: "UnitTestOper", AsGoTierRateLimitDuration: time.Hour * 24 * 365 * 10, Location: "50.1535,-19.165"}}, types.MetadataValidation{SchemaVersion: "0.0.0"}, []byte(`{}`), "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", false); err != nil {
 		t.Errorf("error upserting: %+v", err)
 	}
 }
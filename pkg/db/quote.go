@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/pkg/errors"
+)
+
+// an online provider offering a contract type with the terms needed to price it. rate limits come from the
+// provider's current metadata, durations are in nanoseconds
+type QuoteCandidate struct {
+	Pubkey                     string       `db:"pubkey"`
+	Chain                      string       `db:"chain"`
+	MinContractDuration        int64        `db:"min_contract_duration"`
+	MaxContractDuration        int64        `db:"max_contract_duration"`
+	SubscriptionRate           types.Amount `db:"subscription_rate"`
+	PayAsYouGoRate             types.Amount `db:"paygo_rate"`
+	SubscribeRateLimit         *int64       `db:"subscribe_rate_limit"`
+	SubscribeRateLimitDuration *int64       `db:"subscribe_rate_limit_duration"`
+	PaygoRateLimit             *int64       `db:"paygo_rate_limit"`
+	PaygoRateLimitDuration     *int64       `db:"paygo_rate_limit_duration"`
+	ReputationScore            *float64     `db:"reputation_score"`
+	Uptime                     *float64     `db:"uptime"`
+}
+
+// find online providers for chain with a non zero rate for contractType
+func (d *DirectoryDB) FindQuoteCandidates(chain string, contractType types.ContractType) ([]*QuoteCandidate, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	sql := sqlFindQuoteCandidates + `and p.paygo_rate > 0`
+	if contractType == types.ContractTypeSubscription {
+		sql = sqlFindQuoteCandidates + `and p.subscription_rate > 0`
+	}
+	results := make([]*QuoteCandidate, 0, 64)
+	if err = pgxscan.Select(context.Background(), conn, &results, sql, chain, types.ProviderStatusOnline); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
@@ -0,0 +1,27 @@
+package db
+
+const (
+	// providers with invalid metadata are excluded as in search, those without fetched metadata are quoted
+	// without rate limits
+	sqlFindQuoteCandidates = `
+	select p.pubkey,
+	       p.chain,
+	       coalesce(p.min_contract_duration,0) as min_contract_duration,
+	       coalesce(p.max_contract_duration,0) as max_contract_duration,
+	       coalesce(p.subscription_rate,0) as subscription_rate,
+	       coalesce(p.paygo_rate,0) as paygo_rate,
+	       m.subscribe_rate_limit,
+	       m.subscribe_rate_limit_duration,
+	       m.paygo_rate_limit,
+	       m.paygo_rate_limit_duration,
+	       rep.score as reputation_score,
+	       ps.uptime
+	from providers p
+		left join provider_metadata m on m.provider_id = p.id and m.nonce = p.metadata_nonce
+		left join provider_reputation rep on rep.provider_id = p.id
+		left join provider_probe_stats_v ps on ps.provider_id = p.id
+	where p.chain = $1
+	  and p.status = $2
+	  and coalesce(m.is_valid, true)
+	`
+)
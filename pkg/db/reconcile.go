@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/pkg/errors"
+)
+
+// a directory contract with its provider's key and the highest nonce settled against it
+type ReconcileContract struct {
+	ArkeoContract
+	ProviderPubkey  string `db:"provider_pubkey"`
+	Chain           string `db:"chain"`
+	SettlementNonce int64  `db:"settlement_nonce"`
+}
+
+// one run of the reconciliation against the arkeo api, Finished is nil while running
+type ReconcileRun struct {
+	Entity
+	ChainHeight      int64      `db:"chain_height"`
+	Repair           bool       `db:"repair"`
+	ProvidersChecked int64      `db:"providers_checked"`
+	ContractsChecked int64      `db:"contracts_checked"`
+	Mismatches       int64      `db:"mismatches"`
+	Repaired         int64      `db:"repaired"`
+	Finished         *time.Time `db:"finished"`
+	Error            *string    `db:"error"`
+}
+
+// a field whose value in the directory differs from the chain's
+type ReconcileMismatch struct {
+	Entity
+	RunID          int64  `db:"run_id"`
+	EntityType     string `db:"entity_type"`
+	EntityKey      string `db:"entity_key"`
+	Field          string `db:"field"`
+	DirectoryValue string `db:"directory_value"`
+	ChainValue     string `db:"chain_value"`
+	Repaired       bool   `db:"repaired"`
+}
+
+// find every provider with the fields the chain also holds
+func (d *DirectoryDB) FindAllProviders() ([]*ArkeoProvider, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ArkeoProvider, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindAllProviders); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
+
+func (d *DirectoryDB) FindReconcileContracts() ([]*ReconcileContract, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ReconcileContract, 0, 512)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindReconcileContracts); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
+
+func (d *DirectoryDB) InsertReconcileRun(chainHeight int64, repair bool) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return insert(conn, sqlInsertReconcileRun, chainHeight, repair)
+}
+
+// record a run's totals and mark it finished, runErr is stored when the run failed
+func (d *DirectoryDB) FinishReconcileRun(run *ReconcileRun, runErr error) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	var errText *string
+	if runErr != nil {
+		s := runErr.Error()
+		errText = &s
+	}
+	return update(conn, sqlFinishReconcileRun, run.ID, run.ProvidersChecked, run.ContractsChecked, run.Mismatches,
+		run.Repaired, errText)
+}
+
+func (d *DirectoryDB) InsertReconcileMismatch(m *ReconcileMismatch) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return insert(conn, sqlInsertReconcileMismatch, m.RunID, m.EntityType, m.EntityKey, m.Field, m.DirectoryValue,
+		m.ChainValue, m.Repaired)
+}
+
+// the most recently finished run, nil if none has finished
+func (d *DirectoryDB) FindLatestReconcileRun() (*ReconcileRun, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	run := ReconcileRun{}
+	if err = selectOne(conn, sqlFindLatestReconcileRun, &run); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if run.ID == 0 {
+		return nil, nil
+	}
+	return &run, nil
+}
+
+func (d *DirectoryDB) FindReconcileMismatches(runID int64) ([]*ReconcileMismatch, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ReconcileMismatch, 0, 64)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindReconcileMismatches, runID); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
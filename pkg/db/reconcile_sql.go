@@ -0,0 +1,62 @@
+package db
+
+const (
+	sqlFindAllProviders = `
+	select p.id,
+	       p.created,
+	       p.updated,
+	       p.pubkey,
+	       p.chain,
+	       coalesce(p.bond,0) as bond,
+	       coalesce(p.metadata_uri,'') as metadata_uri,
+	       coalesce(p.metadata_nonce,0) as metadata_nonce,
+	       coalesce(p.status,'Offline') as status,
+	       coalesce(p.min_contract_duration,0) as min_contract_duration,
+	       coalesce(p.max_contract_duration,0) as max_contract_duration,
+	       coalesce(p.subscription_rate,0) as subscription_rate,
+	       coalesce(p.paygo_rate,0) as paygo_rate
+	from providers p
+	order by p.id
+	`
+	sqlFindReconcileContracts = `select ` + contractCols + `,
+	p.pubkey as provider_pubkey,
+	p.chain,
+	coalesce((select max(s.nonce) from contract_settlement_events s where s.contract_id = c.id),0) as settlement_nonce
+	from providers p join contracts c on p.id = c.provider_id
+	order by c.id
+	`
+	sqlInsertReconcileRun = `
+	insert into reconcile_runs(chain_height,repair) values ($1,$2) returning id, created, updated
+	`
+	sqlFinishReconcileRun = `
+	update reconcile_runs
+	set providers_checked = $2,
+	    contracts_checked = $3,
+	    mismatches = $4,
+	    repaired = $5,
+	    error = $6,
+	    finished = now(),
+	    updated = now()
+	where id = $1
+	returning id, created, updated
+	`
+	sqlInsertReconcileMismatch = `
+	insert into reconcile_mismatches(run_id,entity_type,entity_key,field,directory_value,chain_value,repaired)
+	values ($1,$2,$3,$4,$5,$6,$7)
+	returning id, created, updated
+	`
+	sqlFindLatestReconcileRun = `
+	select id, created, updated, chain_height, repair, providers_checked, contracts_checked, mismatches, repaired,
+	       finished, error
+	from reconcile_runs
+	where finished is not null
+	order by id desc
+	limit 1
+	`
+	sqlFindReconcileMismatches = `
+	select id, created, updated, run_id, entity_type, entity_key, field, directory_value, chain_value, repaired
+	from reconcile_mismatches
+	where run_id = $1
+	order by entity_type, entity_key, field
+	`
+)
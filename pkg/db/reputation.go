@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/huandu/go-sqlbuilder"
+	"github.com/pkg/errors"
+)
+
+// per provider inputs to the reputation score
+type ReputationInputs struct {
+	ProviderID       int64   `db:"provider_id"`
+	Pubkey           string  `db:"pubkey"`
+	Chain            string  `db:"chain"`
+	Age              int64   `db:"age"`
+	CurHeight        int64   `db:"cur_height"`
+	Bond             float64 `db:"bond"`
+	SettlementVolume float64 `db:"total_paid"`
+	// paid to the validator sharing the provider's account, see sqlProviderValidatorPaid
+	ValidatorPaid        float64 `db:"validator_paid"`
+	CompletedContracts   int64   `db:"completed_contracts"`
+	EarlyClosedContracts int64   `db:"early_closed_contracts"`
+	// nil when no metadata has been fetched for the current nonce
+	MetadataValid *bool `db:"metadata_valid"`
+}
+
+// composite reputation score (0-100) of a provider and its components (each 0-1)
+type ProviderReputation struct {
+	Entity               `json:"-"`
+	ProviderID           int64    `db:"provider_id" json:"-"`
+	Score                float64  `db:"score"`
+	AgeScore             float64  `db:"age_score"`
+	BondScore            float64  `db:"bond_score"`
+	ContractsScore       float64  `db:"contracts_score"`
+	SettlementScore      float64  `db:"settlement_score"`
+	ValidatorScore       float64  `db:"validator_score"`
+	MetadataScore        *float64 `db:"metadata_score"`
+	CompletedContracts   int64    `db:"completed_contracts"`
+	EarlyClosedContracts int64    `db:"early_closed_contracts"`
+	ComputedHeight       int64    `db:"computed_height"`
+}
+
+// a provider recommended for a chain with its reputation breakdown and, when coordinates were given, distance
+type ProviderRecommendation struct {
+	ArkeoProvider
+	Reputation ProviderReputation
+	DistanceKm *float64 `db:"distance_km"`
+	// reputation score discounted by distance, what recommendations are ordered by
+	Rank float64 `db:"-"`
+}
+
+func (d *DirectoryDB) FindReputationInputs() ([]*ReputationInputs, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	results := make([]*ReputationInputs, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindReputationInputs); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
+
+func (d *DirectoryDB) UpsertProviderReputation(r ProviderReputation) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlUpsertProviderReputation, r.ProviderID, r.Score, r.AgeScore, r.BondScore, r.ContractsScore,
+		r.SettlementScore, r.ValidatorScore, r.MetadataScore, r.CompletedContracts, r.EarlyClosedContracts, r.ComputedHeight)
+}
+
+func (d *DirectoryDB) FindProviderReputation(pubkey string, chain string) (*ProviderReputation, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	reputation := ProviderReputation{}
+	if err = selectOne(conn, sqlFindProviderReputation, &reputation, pubkey, chain); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if reputation.ID == 0 {
+		return nil, nil
+	}
+	return &reputation, nil
+}
+
+// find online providers for the chain offering the tier, with valid metadata and a computed reputation, best first.
+// ordering by distance discounted rank is left to the caller
+func (d *DirectoryDB) FindRecommendationCandidates(criteria types.ProviderRecommendParams) ([]*ProviderRecommendation, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	sb := sqlbuilder.NewSelectBuilder()
+	cols := provSearchCols + `,` + reputationCols
+	if criteria.IsCoordinatesSet {
+		// <@> is in statute miles, note psql using long,lat instead of the normal lat,long
+		cols += fmt.Sprintf(`,(provider_metadata.location<@>point(%.5f,%.5f)) * 1.609344 as distance_km`,
+			criteria.Coordinates.Longitude, criteria.Coordinates.Latitude)
+	}
+	sb.Select(cols).
+		From("providers_v p").
+		Join("provider_reputation rep", "rep.provider_id = p.id").
+		Join("provider_metadata", "p.id = provider_metadata.provider_id and p.metadata_nonce = provider_metadata.nonce").
+		JoinWithOption(sqlbuilder.LeftJoin, "provider_probe_stats_v ps", "p.id = ps.provider_id")
+
+	sb = sb.Where(sb.Equal("p.chain", criteria.Chain))
+	sb = sb.Where(sb.Equal("p.status", types.ProviderStatusOnline))
+	sb = sb.Where("provider_metadata.is_valid")
+	switch criteria.Tier {
+	case types.ServiceTierNone:
+	case types.ServiceTierFree:
+		sb = sb.Where(sb.GreaterThan("provider_metadata.free_rate_limit", 0))
+	case types.ServiceTierSubscription:
+		sb = sb.Where(sb.GreaterThan("p.subscription_rate", 0))
+	case types.ServiceTierPayAsYouGo:
+		sb = sb.Where(sb.GreaterThan("p.paygo_rate", 0))
+	default:
+		return nil, fmt.Errorf("not a valid tier %s", criteria.Tier)
+	}
+	if criteria.IsCoordinatesSet {
+		sb = sb.Where("provider_metadata.location is not null")
+	}
+	sb = sb.OrderBy("rep.score").Desc()
+
+	sql, params := sb.BuildWithFlavor(getFlavor())
+	log.Debugf("sql: %s\n%v", sql, params)
+
+	results := make([]*ProviderRecommendation, 0, 64)
+	if err := pgxscan.Select(context.Background(), conn, &results, sql, params...); err != nil {
+		return nil, errors.Wrapf(err, "error selecting many")
+	}
+	return results, nil
+}
@@ -0,0 +1,84 @@
+package db
+
+const (
+	// aliased to scan into ProviderRecommendation.Reputation
+	reputationCols = `
+	rep.score as "reputation.score",
+	rep.age_score as "reputation.age_score",
+	rep.bond_score as "reputation.bond_score",
+	rep.contracts_score as "reputation.contracts_score",
+	rep.settlement_score as "reputation.settlement_score",
+	rep.validator_score as "reputation.validator_score",
+	rep.metadata_score as "reputation.metadata_score",
+	rep.completed_contracts as "reputation.completed_contracts",
+	rep.early_closed_contracts as "reputation.early_closed_contracts",
+	rep.computed_height as "reputation.computed_height"
+	`
+)
+
+const (
+	// a contract completed if it ran its full duration, whether or not it was closed afterwards. one closed
+	// before height + duration was closed early
+	sqlFindReputationInputs = `
+	select p.id as provider_id,
+	       p.pubkey,
+	       p.chain,
+	       coalesce(p.age,0) as age,
+	       coalesce(p.cur_height,0) as cur_height,
+	       coalesce(p.bond,0) as bond,
+	       coalesce(p.total_paid,0) as total_paid,
+	       ` + sqlProviderValidatorPaid + ` as validator_paid,
+	       (select count(1)
+	        from contracts c
+	        where c.provider_id = p.id
+	          and ((c.closed_height = 0 and c.height + c.duration <= p.cur_height)
+	            or c.closed_height >= c.height + c.duration)) as completed_contracts,
+	       (select count(1)
+	        from contracts c
+	        where c.provider_id = p.id
+	          and c.closed_height > 0
+	          and c.closed_height < c.height + c.duration) as early_closed_contracts,
+	       m.is_valid as metadata_valid
+	from providers_v p
+		left join provider_metadata m on m.provider_id = p.id and m.nonce = p.metadata_nonce
+	`
+	sqlUpsertProviderReputation = `
+	insert into provider_reputation(provider_id,score,age_score,bond_score,contracts_score,settlement_score,validator_score,
+		metadata_score,completed_contracts,early_closed_contracts,computed_height)
+	values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+	on conflict on constraint provider_reputation_prov_uniq
+	do update set score = $2,
+	              age_score = $3,
+	              bond_score = $4,
+	              contracts_score = $5,
+	              settlement_score = $6,
+	              validator_score = $7,
+	              metadata_score = $8,
+	              completed_contracts = $9,
+	              early_closed_contracts = $10,
+	              computed_height = $11,
+	              updated = now()
+	where provider_reputation.provider_id = $1
+	returning id, created, updated
+	`
+	sqlFindProviderReputation = `
+	select r.id,
+	       r.created,
+	       r.updated,
+	       r.provider_id,
+	       r.score,
+	       r.age_score,
+	       r.bond_score,
+	       r.contracts_score,
+	       r.settlement_score,
+	       r.validator_score,
+	       r.metadata_score,
+	       r.completed_contracts,
+	       r.early_closed_contracts,
+	       r.computed_height
+	from provider_reputation r
+		join providers p on p.id = r.provider_id
+	where p.pubkey = $1
+	  and p.chain = $2
+	`
+)
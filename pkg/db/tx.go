@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/pkg/errors"
+)
+
+// a tx which carried an arkeo event or failed with an arkeo message. Signer signed its first message, the authz
+// grantee for MsgExec. Fee is the part of the fee paid in the default denom, FeeCoins all of it
+type Tx struct {
+	Entity    `json:"-"`
+	TxID      string       `db:"txid"`
+	Height    int64        `db:"height"`
+	Signer    string       `db:"signer"`
+	FeePayer  string       `db:"fee_payer"`
+	Memo      string       `db:"memo"`
+	Fee       types.Amount `db:"fee"`
+	FeeCoins  string       `db:"fee_coins"`
+	GasWanted int64        `db:"gas_wanted"`
+	GasUsed   int64        `db:"gas_used"`
+	Code      uint32       `db:"code"`
+	Success   bool         `db:"success"`
+}
+
+// fees and gas paid for the arkeo txs of an account, Pubkey is empty when the account is known by address alone
+type AccountFees struct {
+	Pubkey        string       `db:"pubkey"`
+	Address       string       `db:"address"`
+	TxCount       int64        `db:"tx_count"`
+	FailedTxCount int64        `db:"failed_tx_count"`
+	FeesPaid      types.Amount `db:"fees_paid"`
+	GasWanted     int64        `db:"gas_wanted"`
+	GasUsed       int64        `db:"gas_used"`
+}
+
+// accounts fees can be totalled for
+type FeeRole string
+
+var (
+	FeeRoleProvider FeeRole = "provider"
+	FeeRoleClient   FeeRole = "client"
+)
+
+func (d *DirectoryDB) UpsertTx(tx Tx) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlUpsertTx, tx.TxID, tx.Height, tx.Signer, tx.FeePayer, tx.Memo, tx.Fee, tx.FeeCoins,
+		tx.GasWanted, tx.GasUsed, tx.Code, tx.Success)
+}
+
+// fees paid by the account with address
+func (d *DirectoryDB) FindAccountFees(address string) (*AccountFees, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	fees := AccountFees{}
+	if err = selectOne(conn, sqlFindAccountFees, &fees, address); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	return &fees, nil
+}
+
+// fees paid by each provider or client which has paid any, most fees first
+func (d *DirectoryDB) FindFeesByRole(role FeeRole) ([]*AccountFees, error) {
+	var sql string
+	switch role {
+	case FeeRoleProvider:
+		sql = sqlFindProviderFees
+	case FeeRoleClient:
+		sql = sqlFindClientFees
+	default:
+		return nil, fmt.Errorf("not a valid fee role %s", role)
+	}
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	results := make([]*AccountFees, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sql); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
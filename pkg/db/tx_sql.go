@@ -0,0 +1,55 @@
+package db
+
+const accountFeesCols = `
+	count(t.id) as tx_count,
+	count(t.id) filter (where not t.success) as failed_tx_count,
+	coalesce(sum(t.fee),0) as fees_paid,
+	coalesce(sum(t.gas_wanted),0) as gas_wanted,
+	coalesce(sum(t.gas_used),0) as gas_used
+`
+
+const (
+	sqlUpsertTx = `
+	insert into txs(txid,height,signer,fee_payer,memo,fee,fee_coins,gas_wanted,gas_used,code,success)
+	values ($1,$2,nullif($3,''),nullif($4,''),nullif($5,''),$6,$7,$8,$9,$10,$11)
+	on conflict on constraint txs_txid_key
+	do update set height = $2,
+	              signer = nullif($3,''),
+	              fee_payer = nullif($4,''),
+	              memo = nullif($5,''),
+	              fee = $6,
+	              fee_coins = $7,
+	              gas_wanted = $8,
+	              gas_used = $9,
+	              code = $10,
+	              success = $11,
+	              updated = now()
+	where txs.txid = $1
+	returning id, created, updated
+	`
+	sqlFindAccountFees = `
+	select coalesce((select pa.pubkey from pubkey_addresses pa where pa.address = $1 order by pa.id limit 1),'') as pubkey,
+	       $1::text as address,
+	` + accountFeesCols + `
+	from txs t
+	where t.fee_payer = $1
+	`
+	sqlFindProviderFees = `
+	select pa.pubkey, pa.address,
+	` + accountFeesCols + `
+	from pubkey_addresses pa
+		join txs t on t.fee_payer = pa.address
+	where exists (select 1 from providers p where p.pubkey = pa.pubkey)
+	group by pa.pubkey, pa.address
+	order by fees_paid desc, pa.pubkey
+	`
+	sqlFindClientFees = `
+	select pa.pubkey, pa.address,
+	` + accountFeesCols + `
+	from pubkey_addresses pa
+		join txs t on t.fee_payer = pa.address
+	where exists (select 1 from contracts c where c.client_pubkey = pa.pubkey or c.delegate_pubkey = pa.pubkey)
+	group by pa.pubkey, pa.address
+	order by fees_paid desc, pa.pubkey
+	`
+)
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestFindFees(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, err := New(config)
+	if err != nil {
+		t.Errorf("error getting db: %+v", err)
+		t.FailNow()
+	}
+
+	for _, role := range []FeeRole{FeeRoleProvider, FeeRoleClient} {
+		results, err := db.FindFeesByRole(role)
+		if err != nil {
+			t.Errorf("error finding %s fees: %+v", role, err)
+			t.FailNow()
+		}
+		for _, f := range results {
+			log.Infof("%s %s: %d txs, %s paid", role, f.Address, f.TxCount, f.FeesPaid)
+		}
+	}
+
+	fees, err := db.FindAccountFees("tarkeo1notanaccount")
+	if err != nil {
+		t.Errorf("error finding account fees: %+v", err)
+		t.FailNow()
+	}
+	if fees.TxCount != 0 || !fees.FeesPaid.IsZero() {
+		t.Errorf("expected no fees, got %+v", fees)
+	}
+}
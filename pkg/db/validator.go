@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/pkg/errors"
+)
+
+// a validator that has received payouts
+type Validator struct {
+	Entity   `json:"-"`
+	Operator string `db:"operator"`
+	// account address sharing the operator's key, empty until the indexer has derived it
+	Address           string `db:"address"`
+	FirstPayoutHeight int64  `db:"first_payout_height"`
+}
+
+// a validator with its payout totals
+type ValidatorSummary struct {
+	Validator
+	PayoutCount      int64        `db:"payout_count"`
+	TotalPaid        types.Amount `db:"total_paid"`
+	LastPayoutHeight int64        `db:"last_payout_height"`
+	// pubkey of the provider operated with the validator's key, empty if there is none
+	ProviderPubkey string `db:"provider_pubkey"`
+}
+
+// a single payout to a validator, BlockTime is nil when the block has not been indexed
+type ValidatorPayout struct {
+	Height    int64        `db:"height"`
+	TxID      string       `db:"txid"`
+	Paid      types.Amount `db:"paid"`
+	BlockTime *time.Time   `db:"block_time"`
+}
+
+// payouts to a validator within the period starting at Period
+type ValidatorPayoutPeriod struct {
+	Period      time.Time    `db:"period"`
+	PayoutCount int64        `db:"payout_count"`
+	Paid        types.Amount `db:"paid"`
+}
+
+// record a validator seen in a payout at height, keeping the earliest payout height. an empty address leaves a
+// previously derived one in place
+func (d *DirectoryDB) UpsertValidator(operator, address string, height int64) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlUpsertValidator, operator, address, height)
+}
+
+func (d *DirectoryDB) UpsertValidatorPayoutEvent(validatorID int64, evt types.ValidatorPayoutEvent) (*Entity, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+
+	return upsert(conn, sqlUpsertValidatorPayoutEvent, evt.Validator, evt.Height, evt.Paid, validatorID, evt.TxID)
+}
+
+// validators whose account address has not been derived
+func (d *DirectoryDB) FindValidatorsWithoutAddress() ([]*Validator, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*Validator, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindValidatorsWithoutAddress); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
+
+// all validators with their payout totals, largest total first
+func (d *DirectoryDB) FindValidators() ([]*ValidatorSummary, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ValidatorSummary, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindValidators); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
+
+// find a validator by operator or account address, nil if not found
+func (d *DirectoryDB) FindValidator(addr string) (*ValidatorSummary, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	validator := ValidatorSummary{}
+	if err = selectOne(conn, sqlFindValidator, &validator, addr); err != nil {
+		return nil, errors.Wrapf(err, "error selecting")
+	}
+	// not found
+	if validator.ID == 0 {
+		return nil, nil
+	}
+	return &validator, nil
+}
+
+// the most recent payouts to a validator, newest first
+func (d *DirectoryDB) FindValidatorPayouts(validatorID int64, limit int) ([]*ValidatorPayout, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ValidatorPayout, 0, limit)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindValidatorPayouts, validatorID, limit); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
+
+// a validator's payouts totalled per day, week or month, newest first. payouts in blocks which have not been
+// indexed are left out as their period is unknown
+func (d *DirectoryDB) FindValidatorPayoutPeriods(validatorID int64, period string) ([]*ValidatorPayoutPeriod, error) {
+	conn, err := d.getConnection()
+	defer conn.Release()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error obtaining db connection")
+	}
+	results := make([]*ValidatorPayoutPeriod, 0, 128)
+	if err = pgxscan.Select(context.Background(), conn, &results, sqlFindValidatorPayoutPeriods, validatorID, period); err != nil {
+		return nil, errors.Wrapf(err, "error scanning")
+	}
+	return results, nil
+}
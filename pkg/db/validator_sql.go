@@ -0,0 +1,89 @@
+package db
+
+// total payouts to the validator operated with provider p's key
+const sqlProviderValidatorPaid = `coalesce((
+	select sum(vpe.paid)
+	from validator_payout_events vpe
+		join validators v on v.id = vpe.validator_id
+		join pubkey_addresses pa on pa.address = v.address
+	where pa.pubkey = p.pubkey
+),0)`
+
+const validatorSummaryCols = `
+	v.id,
+	v.created,
+	v.updated,
+	v.operator,
+	coalesce(v.address,'') as address,
+	v.first_payout_height,
+	count(vpe.id) as payout_count,
+	coalesce(sum(vpe.paid),0) as total_paid,
+	coalesce(max(vpe.height),0) as last_payout_height,
+	coalesce((select pa.pubkey from pubkey_addresses pa where pa.address = v.address order by pa.id limit 1),'') as provider_pubkey
+`
+
+var (
+	sqlUpsertValidator = `
+	insert into validators(operator,address,first_payout_height)
+	values ($1,nullif($2,''),$3)
+	on conflict on constraint validators_operator_key
+	do update set address = coalesce(nullif($2,''), validators.address),
+	              first_payout_height = least(validators.first_payout_height, $3),
+	              updated = now()
+	where validators.operator = $1
+	returning id, created, updated
+	`
+	sqlUpsertValidatorPayoutEvent = `
+	insert into validator_payout_events(validator,height,paid,validator_id,txid)
+	values ($1,$2,$3,$4,nullif($5,''))
+	on conflict on constraint validator_payout_evts_validator_height_key
+	do update set validator_id = $4,
+	              txid = coalesce(nullif($5,''), validator_payout_events.txid),
+	              updated = now()
+	where validator_payout_events.validator = $1
+	  and validator_payout_events.height = $2
+	returning id, created, updated
+	`
+	sqlFindValidatorsWithoutAddress = `
+	select id, created, updated, operator, '' as address, first_payout_height
+	from validators
+	where address is null
+	`
+	sqlFindValidators = `
+	select ` + validatorSummaryCols + `
+	from validators v
+		left join validator_payout_events vpe on vpe.validator_id = v.id
+	group by v.id
+	order by total_paid desc, v.id
+	`
+	sqlFindValidator = `
+	select ` + validatorSummaryCols + `
+	from validators v
+		left join validator_payout_events vpe on vpe.validator_id = v.id
+	where v.operator = $1 or v.address = $1
+	group by v.id
+	order by v.id
+	limit 1
+	`
+	sqlFindValidatorPayouts = `
+	select vpe.height,
+	       coalesce(vpe.txid,'') as txid,
+	       coalesce(vpe.paid,0) as paid,
+	       b.block_time
+	from validator_payout_events vpe
+		left join blocks b on b.height = vpe.height
+	where vpe.validator_id = $1
+	order by vpe.height desc
+	limit $2
+	`
+	sqlFindValidatorPayoutPeriods = `
+	select date_trunc($2, b.block_time) as period,
+	       count(1) as payout_count,
+	       coalesce(sum(vpe.paid),0) as paid
+	from validator_payout_events vpe
+		join blocks b on b.height = vpe.height
+	where vpe.validator_id = $1
+	group by 1
+	order by 1 desc
+	`
+)
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestFindValidators(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, err := New(config)
+	if err != nil {
+		t.Errorf("error getting db: %+v", err)
+		t.FailNow()
+	}
+
+	validators, err := db.FindValidators()
+	if err != nil {
+		t.Errorf("error finding validators: %+v", err)
+		t.FailNow()
+	}
+	for _, v := range validators {
+		log.Infof("%s (%s): %d payouts, %s paid", v.Operator, v.Address, v.PayoutCount, v.TotalPaid)
+		periods, err := db.FindValidatorPayoutPeriods(v.ID, "week")
+		if err != nil {
+			t.Errorf("error finding payout periods of %s: %+v", v.Operator, err)
+			t.FailNow()
+		}
+		for _, p := range periods {
+			log.Infof("  %s: %d payouts, %s paid", p.Period, p.PayoutCount, p.Paid)
+		}
+	}
+
+	v, err := db.FindValidator("tarkeovaloper1notavalidator")
+	if err != nil {
+		t.Errorf("error finding validator: %+v", err)
+	}
+	if v != nil {
+		t.Errorf("expected no validator, got %s", v.Operator)
+	}
+}
@@ -0,0 +1,62 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/db"
+)
+
+// DBCheck pings the pool and reports its usage
+func DBCheck(d *db.DirectoryDB) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		stat := d.PoolStats()
+		details := map[string]interface{}{
+			"TotalConns":    stat.TotalConns(),
+			"AcquiredConns": stat.AcquiredConns(),
+			"IdleConns":     stat.IdleConns(),
+			"MaxConns":      stat.MaxConns(),
+		}
+		return details, d.Ping(ctx)
+	}
+}
+
+// SchemaCheck fails while migrations this build depends on have not been applied
+func SchemaCheck(d *db.DirectoryDB) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		version, err := d.FindSchemaVersion()
+		if err != nil {
+			return nil, err
+		}
+		details := map[string]interface{}{"Version": version, "Expected": db.SchemaVersion}
+		if version < db.SchemaVersion {
+			return details, fmt.Errorf("schema version %d is behind expected %d, migrations pending", version, db.SchemaVersion)
+		}
+		return details, nil
+	}
+}
+
+// FreshnessCheck fails when the latest indexed block is older than maxAge
+func FreshnessCheck(d *db.DirectoryDB, maxAge time.Duration) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		block, err := d.FindLatestBlock()
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("no blocks indexed")
+		}
+		age := time.Since(block.BlockTime)
+		details := map[string]interface{}{
+			"LatestHeight":    block.Height,
+			"LatestBlockTime": block.BlockTime,
+			"AgeSeconds":      int64(age.Seconds()),
+			"MaxAgeSeconds":   int64(maxAge.Seconds()),
+		}
+		if age > maxAge {
+			return details, fmt.Errorf("latest block %d is %s old, exceeds %s", block.Height, age.Round(time.Second), maxAge)
+		}
+		return details, nil
+	}
+}
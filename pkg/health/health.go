@@ -0,0 +1,138 @@
+// Package health runs dependency checks for liveness and readiness endpoints.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusOK        Status = "ok"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// DefaultTimeout bounds each check, a check still running after it is unhealthy
+const DefaultTimeout = 5 * time.Second
+
+// Check reports details about a component, or an error when it is unhealthy
+type Check func(ctx context.Context) (details map[string]interface{}, err error)
+
+type Component struct {
+	Status  Status
+	Error   string                 `json:",omitempty"`
+	Details map[string]interface{} `json:",omitempty"`
+}
+
+// swagger:model HealthReport
+type Report struct {
+	Status     Status
+	Components map[string]*Component `json:",omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+type Checker struct {
+	timeout time.Duration
+	checks  []namedCheck
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a check reported under name, checks run concurrently
+func (c *Checker) Add(name string, check Check) *Checker {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+	return c
+}
+
+// Run all checks, the report is unhealthy when any component is
+func (c *Checker) Run(ctx context.Context) *Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	report := &Report{Status: StatusOK, Components: make(map[string]*Component, len(c.checks))}
+	results := make([]*Component, len(c.checks))
+	wg := sync.WaitGroup{}
+	for i := range c.checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, c.checks[i].check)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, nc := range c.checks {
+		report.Components[nc.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusUnhealthy
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) *Component {
+	type result struct {
+		details map[string]interface{}
+		err     error
+	}
+	// buffered so a check finishing after the timeout does not block
+	resultChan := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				resultChan <- result{err: fmt.Errorf("check panicked: %v", r)}
+			}
+		}()
+		details, err := check(ctx)
+		resultChan <- result{details: details, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return &Component{Status: StatusUnhealthy, Error: "check timed out"}
+	case res := <-resultChan:
+		if res.err != nil {
+			return &Component{Status: StatusUnhealthy, Error: res.err.Error(), Details: res.details}
+		}
+		return &Component{Status: StatusOK, Details: res.details}
+	}
+}
+
+// LiveHandler reports the process is up, it checks no dependencies so a restart cannot fix a dependency outage
+func LiveHandler(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, &Report{Status: StatusOK})
+}
+
+// ReadyHandler runs the checks and responds 503 when any fails
+func (c *Checker) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, c.Run(r.Context()))
+}
+
+func writeReport(w http.ResponseWriter, report *Report) {
+	code := http.StatusOK
+	if report.Status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	response, err := json.Marshal(report)
+	if err != nil {
+		code = http.StatusInternalServerError
+		response = []byte(`{"Status":"unhealthy"}`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
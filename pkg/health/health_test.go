@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckerRun(t *testing.T) {
+	ok := func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"x": 1}, nil
+	}
+	failing := func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, errors.New("down")
+	}
+	panicking := func(ctx context.Context) (map[string]interface{}, error) {
+		panic("nil conn")
+	}
+	slow := func(ctx context.Context) (map[string]interface{}, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}
+
+	report := NewChecker(time.Second).Add("a", ok).Run(context.Background())
+	if report.Status != StatusOK || report.Components["a"].Status != StatusOK {
+		t.Errorf("expected ok report, got %+v", report)
+	}
+
+	report = NewChecker(100*time.Millisecond).
+		Add("ok", ok).
+		Add("failing", failing).
+		Add("panicking", panicking).
+		Add("slow", slow).
+		Run(context.Background())
+	if report.Status != StatusUnhealthy {
+		t.Errorf("expected unhealthy report, got %s", report.Status)
+	}
+	if report.Components["ok"].Status != StatusOK {
+		t.Errorf("expected ok component to be ok")
+	}
+	for name, msg := range map[string]string{"failing": "down", "panicking": "check panicked: nil conn", "slow": "check timed out"} {
+		c := report.Components[name]
+		if c.Status != StatusUnhealthy || c.Error != msg {
+			t.Errorf("%s: expected unhealthy %q, got %s %q", name, msg, c.Status, c.Error)
+		}
+	}
+}
+
+func TestReadyHandler(t *testing.T) {
+	failing := func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, errors.New("down")
+	}
+	cases := []struct {
+		checker *Checker
+		code    int
+	}{
+		{NewChecker(time.Second), http.StatusOK},
+		{NewChecker(time.Second).Add("db", failing), http.StatusServiceUnavailable},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		c.checker.ReadyHandler(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+		if rec.Code != c.code {
+			t.Errorf("expected %d, got %d: %s", c.code, rec.Code, rec.Body.String())
+		}
+	}
+}
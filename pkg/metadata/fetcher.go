@@ -0,0 +1,361 @@
+package metadata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/tracing"
+	"github.com/pkg/errors"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+const (
+	DefaultMaxBytes     = 1e6
+	DefaultTimeout      = 10 * time.Second
+	DefaultMaxRedirects = 3
+	DefaultMaxPerHost   = 2
+	DefaultIPFSGateway  = "https://ipfs.io"
+)
+
+// FetcherConfig configures a Fetcher, zero values are replaced with defaults
+type FetcherConfig struct {
+	MaxBytes     int64
+	Timeout      time.Duration
+	MaxRedirects int
+	// maximum concurrent requests to a single host
+	MaxPerHost int
+	// http(s) gateway ipfs:// uris are read through, as <gateway>/ipfs/<cid>/<path>
+	IPFSGateway string
+	// allow file:// uris, for local development only
+	AllowFile bool
+	// allow connecting to loopback, private and link-local addresses, for local development only
+	AllowPrivateAddresses bool
+}
+
+// Fetcher downloads metadata documents from provider controlled uris. only http(s) and ipfs are allowed by default,
+// connections to non-public addresses are refused at dial time (so after dns resolution and on every redirect),
+// responses are size capped while streaming and must have a json or plain text content type. a uri may pin its
+// content with a "#sha256=<hex digest>" fragment, which is verified after download, and may be accompanied by a
+// detached signature at <uri>.sig (see FetchSignature and VerifySignature)
+type Fetcher struct {
+	config FetcherConfig
+	client *http.Client
+
+	hostsMu sync.Mutex
+	hosts   map[string]chan struct{}
+
+	// checks the resolved "ip:port" before connecting
+	checkAddress func(address string) error
+}
+
+var errBlockedAddress = errors.New("address not allowed")
+
+var errNotFound = errors.New("not found")
+
+// content types accepted from metadata hosts, raw.githubusercontent.com serves json as text/plain
+var allowedContentTypes = map[string]struct{}{
+	"application/json": {},
+	"text/plain":       {},
+}
+
+// detached signatures are base64 text, hosts without a mapping for .sig serve octet-stream
+var signatureContentTypes = map[string]struct{}{
+	"text/plain":               {},
+	"application/octet-stream": {},
+}
+
+// a base64 encoded 64 byte signature with some room for whitespace
+const signatureMaxBytes = 1024
+
+func NewFetcher(config FetcherConfig) *Fetcher {
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = DefaultMaxBytes
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxRedirects <= 0 {
+		config.MaxRedirects = DefaultMaxRedirects
+	}
+	if config.MaxPerHost <= 0 {
+		config.MaxPerHost = DefaultMaxPerHost
+	}
+	if config.IPFSGateway == "" {
+		config.IPFSGateway = DefaultIPFSGateway
+	}
+
+	f := &Fetcher{config: config, hosts: make(map[string]chan struct{})}
+	f.checkAddress = func(address string) error {
+		if config.AllowPrivateAddresses {
+			return nil
+		}
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return errors.Wrapf(err, "error splitting address %s", address)
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || IsBlockedIP(ip) {
+			return errors.Wrapf(errBlockedAddress, "%s", host)
+		}
+		return nil
+	}
+
+	dialer := &net.Dialer{
+		Timeout: config.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return f.checkAddress(address)
+		},
+	}
+	f.client = &http.Client{
+		Timeout: config.Timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // a proxy would connect on our behalf, bypassing the address check
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   config.Timeout,
+			ResponseHeaderTimeout: config.Timeout,
+			MaxIdleConnsPerHost:   config.MaxPerHost,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > config.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", config.MaxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to scheme %s not allowed", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+	return f
+}
+
+// ValidateURI checks the uri parses and uses a scheme this fetcher will read
+func (f *Fetcher) ValidateURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return errors.Wrapf(err, "error parsing uri")
+	}
+	if _, err = expectedHash(u); err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("missing host in %s", uri)
+		}
+		return nil
+	case "ipfs":
+		if u.Host == "" {
+			return fmt.Errorf("missing cid in %s", uri)
+		}
+		return nil
+	case "file":
+		if !f.config.AllowFile {
+			return fmt.Errorf("file uris are not allowed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("scheme %s not allowed", u.Scheme)
+	}
+}
+
+// Fetch reads the raw document at uri, verifying its digest if the uri pins one
+func (f *Fetcher) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	if err := f.ValidateURI(uri); err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(uri)
+	expected, _ := expectedHash(u)
+
+	raw, err := f.read(ctx, u, f.config.MaxBytes, allowedContentTypes)
+	if err != nil {
+		return nil, err
+	}
+	if expected != "" {
+		if actual := ContentHash(raw); actual != expected {
+			return nil, fmt.Errorf("content hash %s does not match expected %s", actual, expected)
+		}
+	}
+	return raw, nil
+}
+
+// FetchSignature reads the detached signature published alongside the document at uri as <uri>.sig (any
+// "#sha256=" fragment dropped). returns nil if the provider doesn't publish one
+func (f *Fetcher) FetchSignature(ctx context.Context, uri string) ([]byte, error) {
+	if err := f.ValidateURI(uri); err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(uri)
+	u.Fragment, u.RawFragment = "", ""
+	u.Path, u.RawPath = u.Path+".sig", ""
+
+	sig, err := f.read(ctx, u, signatureMaxBytes, signatureContentTypes)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	return sig, err
+}
+
+func (f *Fetcher) read(ctx context.Context, u *url.URL, maxBytes int64, contentTypes map[string]struct{}) ([]byte, error) {
+	switch u.Scheme {
+	case "file":
+		return f.readFile(u, maxBytes)
+	case "ipfs":
+		gateway, err := f.gatewayURL(u)
+		if err != nil {
+			return nil, err
+		}
+		u = gateway
+	}
+
+	release, err := f.acquireHost(ctx, strings.ToLower(u.Host))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return f.readNetwork(ctx, u, maxBytes, contentTypes)
+}
+
+// rewrite ipfs://<cid>/<path> to <gateway>/ipfs/<cid>/<path>
+func (f *Fetcher) gatewayURL(u *url.URL) (*url.URL, error) {
+	gateway, err := url.Parse(f.config.IPFSGateway)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing ipfs gateway %s", f.config.IPFSGateway)
+	}
+	return gateway.JoinPath("ipfs", u.Host, u.Path), nil
+}
+
+// ContentHash is the hex encoded sha256 digest of raw
+func ContentHash(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// the digest pinned by a "#sha256=<hex>" fragment, "" if the uri doesn't pin one
+func expectedHash(u *url.URL) (string, error) {
+	if u.Fragment == "" {
+		return "", nil
+	}
+	algo, digest, ok := strings.Cut(u.Fragment, "=")
+	if !ok || algo != "sha256" {
+		return "", fmt.Errorf("unsupported fragment %s, expected sha256=<hex>", u.Fragment)
+	}
+	digest = strings.ToLower(digest)
+	if b, err := hex.DecodeString(digest); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid sha256 digest %s", digest)
+	}
+	return digest, nil
+}
+
+func (f *Fetcher) readNetwork(ctx context.Context, u *url.URL, maxBytes int64, contentTypes map[string]struct{}) (raw []byte, err error) {
+	ctx, span := tracing.Start(ctx, "metadata.fetch",
+		semconv.HTTPMethodKey.String(http.MethodGet),
+		semconv.HTTPURLKey.String(u.Redacted()),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating request")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error requesting %s", u.Redacted())
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.Wrapf(errNotFound, "http status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http status %d", resp.StatusCode)
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing content type %q", resp.Header.Get("Content-Type"))
+	}
+	if _, ok := contentTypes[mediaType]; !ok {
+		return nil, fmt.Errorf("content type %s not allowed", mediaType)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("content length %d exceeds max bytes %d", resp.ContentLength, maxBytes)
+	}
+	return readCapped(resp.Body, maxBytes)
+}
+
+func (f *Fetcher) readFile(u *url.URL, maxBytes int64) ([]byte, error) {
+	full := fmt.Sprintf("/%s%s", u.Host, u.Path)
+	file, err := os.Open(full)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(errNotFound, "file %s", full)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading file %s", full)
+	}
+	defer file.Close()
+	return readCapped(file, maxBytes)
+}
+
+// read at most maxBytes from r, erroring rather than truncating if there is more
+func readCapped(r io.Reader, maxBytes int64) ([]byte, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading body")
+	}
+	if int64(len(raw)) > maxBytes {
+		return nil, fmt.Errorf("max bytes %d exceeded", maxBytes)
+	}
+	return raw, nil
+}
+
+// block until fewer than MaxPerHost requests to host are in flight, the returned func releases the slot
+func (f *Fetcher) acquireHost(ctx context.Context, host string) (func(), error) {
+	f.hostsMu.Lock()
+	sem, ok := f.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, f.config.MaxPerHost)
+		f.hosts[host] = sem
+	}
+	f.hostsMu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "waiting for %s", host)
+	}
+}
+
+// shared address space (RFC 6598), not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsBlockedIP reports whether ip is not a public unicast address
+func IsBlockedIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		if ip4[0] == 0 || sharedAddressSpace.Contains(ip4) {
+			return true
+		}
+	}
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast()
+}
@@ -0,0 +1,239 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func sampleServer(t *testing.T) *httptest.Server {
+	raw, err := os.ReadFile("../../docs/sample-metadata.json")
+	if err != nil {
+		t.Fatalf("error reading sample metadata: %+v", err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metadata.json", "/ipfs/bafytestcid/metadata.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(raw)
+		case "/metadata.json.sig":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("c2lnbmF0dXJl\n"))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write(raw)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestFetchMetadata(t *testing.T) {
+	srv := sampleServer(t)
+	defer srv.Close()
+
+	f := NewFetcher(FetcherConfig{AllowPrivateAddresses: true})
+	raw, err := f.Fetch(context.Background(), srv.URL+"/metadata.json")
+	if err != nil {
+		t.Fatalf("error fetching: %+v", err)
+	}
+	md, _, err := Parse(raw, "")
+	if err != nil {
+		t.Fatalf("error parsing: %+v", err)
+	}
+	if md.Version == "" {
+		t.Error("expected version")
+	}
+
+	f = NewFetcher(FetcherConfig{AllowPrivateAddresses: true, MaxBytes: 1})
+	if _, err = f.Fetch(context.Background(), srv.URL+"/metadata.json"); err == nil {
+		t.Error("expected max bytes error")
+	}
+}
+
+func TestFetchRejectsContentType(t *testing.T) {
+	srv := sampleServer(t)
+	defer srv.Close()
+
+	f := NewFetcher(FetcherConfig{AllowPrivateAddresses: true})
+	if _, err := f.Fetch(context.Background(), srv.URL+"/page.html"); err == nil || !strings.Contains(err.Error(), "content type") {
+		t.Errorf("expected content type error got %v", err)
+	}
+	if _, err := f.Fetch(context.Background(), srv.URL+"/missing.json"); err == nil {
+		t.Error("expected error for 404")
+	}
+}
+
+func TestFetchBlocksPrivateAddresses(t *testing.T) {
+	srv := sampleServer(t)
+	defer srv.Close()
+
+	f := NewFetcher(FetcherConfig{})
+	if _, err := f.Fetch(context.Background(), srv.URL+"/metadata.json"); err == nil || !strings.Contains(err.Error(), errBlockedAddress.Error()) {
+		t.Errorf("expected loopback to be blocked got %v", err)
+	}
+	// hostnames are checked after resolution
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+	if _, err := f.Fetch(context.Background(), fmt.Sprintf("http://localhost:%s/metadata.json", port)); err == nil || !strings.Contains(err.Error(), errBlockedAddress.Error()) {
+		t.Errorf("expected localhost to be blocked got %v", err)
+	}
+}
+
+func TestFetchBlocksRedirectTarget(t *testing.T) {
+	target := sampleServer(t)
+	defer target.Close()
+	redirector := httptest.NewServer(http.RedirectHandler(target.URL+"/metadata.json", http.StatusFound))
+	defer redirector.Close()
+
+	f := NewFetcher(FetcherConfig{AllowPrivateAddresses: true})
+	if _, err := f.Fetch(context.Background(), redirector.URL); err != nil {
+		t.Fatalf("expected redirect to be followed: %+v", err)
+	}
+
+	// treat the redirect target as a private address, the redirect must not reach it
+	targetAddr := strings.TrimPrefix(target.URL, "http://")
+	f = NewFetcher(FetcherConfig{AllowPrivateAddresses: true})
+	f.checkAddress = func(address string) error {
+		if address == targetAddr {
+			return errBlockedAddress
+		}
+		return nil
+	}
+	if _, err := f.Fetch(context.Background(), redirector.URL); err == nil || !strings.Contains(err.Error(), errBlockedAddress.Error()) {
+		t.Errorf("expected redirect target to be blocked got %v", err)
+	}
+}
+
+func TestFetchSchemes(t *testing.T) {
+	path, err := filepath.Abs("../../docs/sample-metadata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewFetcher(FetcherConfig{})
+	for _, uri := range []string{"file://" + path, "ftp://example.com/metadata.json", "gopher://example.com", "not a uri"} {
+		if _, err := f.Fetch(context.Background(), uri); err == nil {
+			t.Errorf("expected %s to be rejected", uri)
+		}
+	}
+
+	f = NewFetcher(FetcherConfig{AllowFile: true})
+	if _, err := f.Fetch(context.Background(), "file://"+path); err != nil {
+		t.Errorf("expected file uri to be allowed: %+v", err)
+	}
+}
+
+func TestFetchPerHostConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher(FetcherConfig{AllowPrivateAddresses: true, MaxPerHost: 1})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.Fetch(context.Background(), srv.URL); err != nil {
+				t.Errorf("error fetching: %+v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxInFlight != 1 {
+		t.Errorf("expected at most 1 request in flight got %d", maxInFlight)
+	}
+}
+
+func TestFetchContentHash(t *testing.T) {
+	srv := sampleServer(t)
+	defer srv.Close()
+	raw, err := os.ReadFile("../../docs/sample-metadata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := ContentHash(raw)
+
+	f := NewFetcher(FetcherConfig{AllowPrivateAddresses: true})
+	if _, err = f.Fetch(context.Background(), srv.URL+"/metadata.json#sha256="+strings.ToUpper(hash)); err != nil {
+		t.Errorf("expected pinned hash to verify: %+v", err)
+	}
+	wrong := ContentHash([]byte("something else"))
+	if _, err = f.Fetch(context.Background(), srv.URL+"/metadata.json#sha256="+wrong); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected hash mismatch got %v", err)
+	}
+	for _, fragment := range []string{"#md5=abc", "#sha256=xyz", "#sha256=abcd"} {
+		if err = f.ValidateURI(srv.URL + "/metadata.json" + fragment); err == nil {
+			t.Errorf("expected fragment %s to be rejected", fragment)
+		}
+	}
+}
+
+func TestFetchIPFS(t *testing.T) {
+	srv := sampleServer(t)
+	defer srv.Close()
+
+	f := NewFetcher(FetcherConfig{AllowPrivateAddresses: true, IPFSGateway: srv.URL})
+	raw, err := f.Fetch(context.Background(), "ipfs://bafytestcid/metadata.json")
+	if err != nil {
+		t.Fatalf("error fetching through gateway: %+v", err)
+	}
+	if _, err = f.Fetch(context.Background(), "ipfs://bafytestcid/metadata.json#sha256="+ContentHash(raw)); err != nil {
+		t.Errorf("expected pinned ipfs uri to verify: %+v", err)
+	}
+	if err = f.ValidateURI("ipfs:///metadata.json"); err == nil {
+		t.Error("expected missing cid to be rejected")
+	}
+}
+
+func TestFetchSignature(t *testing.T) {
+	srv := sampleServer(t)
+	defer srv.Close()
+
+	f := NewFetcher(FetcherConfig{AllowPrivateAddresses: true})
+	sig, err := f.FetchSignature(context.Background(), srv.URL+"/metadata.json#sha256="+ContentHash([]byte("{}")))
+	if err != nil {
+		t.Fatalf("error fetching signature: %+v", err)
+	}
+	if string(sig) != "c2lnbmF0dXJl\n" {
+		t.Errorf("unexpected signature %q", sig)
+	}
+	if sig, err = f.FetchSignature(context.Background(), srv.URL+"/other.json"); err != nil || sig != nil {
+		t.Errorf("expected no signature got %q %v", sig, err)
+	}
+}
+
+func TestIsBlockedIP(t *testing.T) {
+	blocked := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fc00::1", "::ffff:127.0.0.1", "224.0.0.1"}
+	for _, s := range blocked {
+		if !IsBlockedIP(net.ParseIP(s)) {
+			t.Errorf("expected %s to be blocked", s)
+		}
+	}
+	allowed := []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"}
+	for _, s := range allowed {
+		if IsBlockedIP(net.ParseIP(s)) {
+			t.Errorf("expected %s to be allowed", s)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Parsing, normalization and validation of provider metadata documents (see docs/sample-metadata.json)
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arkeonetwork/directory/pkg/sentinel"
+	"github.com/arkeonetwork/directory/pkg/types"
+	"github.com/arkeonetwork/directory/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+var durationFields = []string{
+	"free_tier_rate_limit_duration",
+	"subscription_tier_rate_limit_duration",
+	"pay_as_you_go_tier_rate_limit_duration",
+}
+
+var rateLimitFields = []string{
+	"free_tier_rate_limit",
+	"subscription_tier_rate_limit",
+	"pay_as_you_go_tier_rate_limit",
+}
+
+// numeric durations below this are taken to be seconds rather than time.Duration nanoseconds
+const minNanosecondDuration = int64(time.Millisecond)
+
+// Parse decodes raw into metadata, normalizing durations and coordinates, and validates the result against
+// the schema for the document's version. providerPubkey is the bonded provider the document is published for.
+// an error is only returned when raw can't be decoded at all, validation failures are reported in the result
+func Parse(raw []byte, providerPubkey string) (*sentinel.Metadata, *types.MetadataValidation, error) {
+	doc := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, nil, errors.Wrapf(err, "error decoding metadata")
+	}
+
+	validation := &types.MetadataValidation{}
+	version, _ := doc["version"].(string)
+	s, ok := schemaFor(version)
+	if !ok {
+		validation.Warnings = append(validation.Warnings, fmt.Sprintf("unrecognized version %q, validating against schema %s", version, s.Version))
+	}
+	validation.SchemaVersion = s.Version
+
+	config, ok := doc["config"].(map[string]interface{})
+	if !ok {
+		validation.Errors = append(validation.Errors, "missing config object")
+		config = map[string]interface{}{}
+	}
+
+	for _, field := range s.Required {
+		if isEmpty(config[field]) {
+			validation.Errors = append(validation.Errors, fmt.Sprintf("missing required field %s", field))
+		}
+	}
+	for _, field := range s.Recommended {
+		if isEmpty(config[field]) {
+			validation.Warnings = append(validation.Warnings, fmt.Sprintf("missing recommended field %s", field))
+		}
+	}
+
+	for _, field := range durationFields {
+		normalizeDuration(config, field, validation)
+	}
+	for _, field := range rateLimitFields {
+		normalizeRateLimit(config, field, validation)
+	}
+	normalizeLocation(config, validation)
+	normalizeString(config, "port", validation)
+
+	if pubkey, _ := config["provider_pubkey"].(string); pubkey != "" && providerPubkey != "" && pubkey != providerPubkey {
+		validation.Errors = append(validation.Errors, fmt.Sprintf("provider_pubkey %s does not match provider %s", pubkey, providerPubkey))
+	}
+
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error encoding normalized metadata")
+	}
+	result := &sentinel.Metadata{}
+	if err = json.Unmarshal(normalized, result); err != nil {
+		return nil, nil, errors.Wrapf(err, "error unmarshaling normalized metadata")
+	}
+	return result, validation, nil
+}
+
+func isEmpty(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	}
+	return false
+}
+
+// durations are time.Duration nanoseconds. small numbers are assumed to be seconds and strings
+// such as "1m" are parsed with time.ParseDuration
+func normalizeDuration(config map[string]interface{}, field string, validation *types.MetadataValidation) {
+	var ns int64
+	switch val := config[field].(type) {
+	case nil:
+		return
+	case json.Number:
+		n, err := val.Int64()
+		if err != nil {
+			f, ferr := val.Float64()
+			if ferr != nil || math.IsInf(f, 0) || f > math.MaxInt64 || f < math.MinInt64 {
+				validation.Errors = append(validation.Errors, fmt.Sprintf("%s is not a valid duration: %s", field, val))
+				delete(config, field)
+				return
+			}
+			n = int64(f)
+		}
+		ns = n
+		if n > 0 && n < minNanosecondDuration {
+			ns = n * int64(time.Second)
+			validation.Warnings = append(validation.Warnings, fmt.Sprintf("%s of %d interpreted as seconds", field, n))
+		}
+	case string:
+		d, err := time.ParseDuration(strings.TrimSpace(val))
+		if err != nil {
+			validation.Errors = append(validation.Errors, fmt.Sprintf("%s is not a valid duration: %q", field, val))
+			delete(config, field)
+			return
+		}
+		ns = int64(d)
+		validation.Warnings = append(validation.Warnings, fmt.Sprintf("%s given as string %q, expected nanoseconds", field, val))
+	default:
+		validation.Errors = append(validation.Errors, fmt.Sprintf("%s is not a valid duration: %v", field, val))
+		delete(config, field)
+		return
+	}
+	if ns < 0 {
+		validation.Errors = append(validation.Errors, fmt.Sprintf("%s must not be negative", field))
+		ns = 0
+	}
+	config[field] = ns
+}
+
+func normalizeRateLimit(config map[string]interface{}, field string, validation *types.MetadataValidation) {
+	var limit int64
+	switch val := config[field].(type) {
+	case nil:
+		return
+	case json.Number:
+		n, err := val.Int64()
+		if err != nil {
+			validation.Errors = append(validation.Errors, fmt.Sprintf("%s must be an integer: %s", field, val))
+			delete(config, field)
+			return
+		}
+		limit = n
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		if err != nil {
+			validation.Errors = append(validation.Errors, fmt.Sprintf("%s must be an integer: %q", field, val))
+			delete(config, field)
+			return
+		}
+		limit = n
+		validation.Warnings = append(validation.Warnings, fmt.Sprintf("%s given as string %q", field, val))
+	default:
+		validation.Errors = append(validation.Errors, fmt.Sprintf("%s must be an integer: %v", field, val))
+		delete(config, field)
+		return
+	}
+	if limit < 0 {
+		validation.Errors = append(validation.Errors, fmt.Sprintf("%s must not be negative", field))
+		limit = 0
+	}
+	config[field] = limit
+}
+
+// location is "latitude,longitude". parentheses and whitespace are stripped and coordinates range checked,
+// anything unusable is dropped with a warning so the provider is simply excluded from distance searches
+func normalizeLocation(config map[string]interface{}, validation *types.MetadataValidation) {
+	raw, ok := config["location"].(string)
+	if !ok || isEmpty(raw) {
+		if config["location"] != nil && !ok {
+			validation.Warnings = append(validation.Warnings, fmt.Sprintf("location is not a string: %v", config["location"]))
+			delete(config, "location")
+		}
+		return
+	}
+	cleaned := strings.NewReplacer("(", "", ")", "", " ", "").Replace(raw)
+	coordinates, err := utils.ParseCoordinates(cleaned)
+	if err != nil {
+		validation.Warnings = append(validation.Warnings, fmt.Sprintf("location %q is not latitude,longitude: %v", raw, err))
+		delete(config, "location")
+		return
+	}
+	if coordinates.Latitude < -90 || coordinates.Latitude > 90 || coordinates.Longitude < -180 || coordinates.Longitude > 180 {
+		validation.Warnings = append(validation.Warnings, fmt.Sprintf("location %q is out of range", raw))
+		delete(config, "location")
+		return
+	}
+	config["location"] = fmt.Sprintf("%.5f,%.5f", coordinates.Latitude, coordinates.Longitude)
+}
+
+// sentinel serializes port as a string, accept numbers as well
+func normalizeString(config map[string]interface{}, field string, validation *types.MetadataValidation) {
+	switch val := config[field].(type) {
+	case nil, string:
+	case json.Number:
+		config[field] = val.String()
+	default:
+		validation.Errors = append(validation.Errors, fmt.Sprintf("%s must be a string: %v", field, val))
+		delete(config, field)
+	}
+}
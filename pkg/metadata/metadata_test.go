@@ -0,0 +1,120 @@
+package metadata
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const samplePubkey = "rkopub1addwnpepqd8ymqlcnv2fnxajwv02mxpgaly2nctjrhegx2d8rwvpd4cjkewuvvzh0m2"
+
+func TestParseSampleMetadata(t *testing.T) {
+	raw, err := os.ReadFile("../../docs/sample-metadata.json")
+	if err != nil {
+		t.Fatalf("error reading sample metadata: %+v", err)
+	}
+	md, validation, err := Parse(raw, samplePubkey)
+	if err != nil {
+		t.Fatalf("error parsing sample metadata: %+v", err)
+	}
+	if !validation.IsValid() {
+		t.Errorf("expected sample metadata to be valid, got errors %v", validation.Errors)
+	}
+	if validation.SchemaVersion != "0.1.0" {
+		t.Errorf("expected schema 0.1.0 got %s", validation.SchemaVersion)
+	}
+	if md.Configuration.FreeTierRateLimitDuration != time.Minute {
+		t.Errorf("expected 1m free tier duration got %s", md.Configuration.FreeTierRateLimitDuration)
+	}
+	if md.Configuration.Location != "40.01760,-105.27000" {
+		t.Errorf("unexpected normalized location %s", md.Configuration.Location)
+	}
+}
+
+func TestParseNormalizesDurations(t *testing.T) {
+	raw := []byte(`{"version":"0.1.0","config":{"moniker":"m","port":3636,"proxy_host":"https://example.com","provider_pubkey":"` + samplePubkey + `",
+		"free_tier_rate_limit":10,"free_tier_rate_limit_duration":60,
+		"subscription_tier_rate_limit":"20","subscription_tier_rate_limit_duration":"2m",
+		"pay_as_you_go_tier_rate_limit":30,"pay_as_you_go_tier_rate_limit_duration":60000000000,
+		"location":"(40.0176, -105.27)"}}`)
+	md, validation, err := Parse(raw, samplePubkey)
+	if err != nil {
+		t.Fatalf("error parsing: %+v", err)
+	}
+	if !validation.IsValid() {
+		t.Fatalf("expected valid, got errors %v", validation.Errors)
+	}
+	c := md.Configuration
+	if c.FreeTierRateLimitDuration != time.Minute || c.SubTierRateLimitDuration != 2*time.Minute || c.AsGoTierRateLimitDuration != time.Minute {
+		t.Errorf("unexpected durations %s %s %s", c.FreeTierRateLimitDuration, c.SubTierRateLimitDuration, c.AsGoTierRateLimitDuration)
+	}
+	if c.SubTierRateLimit != 20 {
+		t.Errorf("expected subscription rate limit 20 got %d", c.SubTierRateLimit)
+	}
+	if c.Port != "3636" {
+		t.Errorf("expected port 3636 got %s", c.Port)
+	}
+	if c.Location != "40.01760,-105.27000" {
+		t.Errorf("unexpected normalized location %s", c.Location)
+	}
+	if len(validation.Warnings) < 3 {
+		t.Errorf("expected warnings for normalized fields, got %v", validation.Warnings)
+	}
+}
+
+func TestParseHardFailures(t *testing.T) {
+	raw := []byte(`{"version":"0.1.0","config":{"moniker":"m","port":"3636","provider_pubkey":"someoneelse","free_tier_rate_limit":-1,"free_tier_rate_limit_duration":"forever"}}`)
+	_, validation, err := Parse(raw, samplePubkey)
+	if err != nil {
+		t.Fatalf("error parsing: %+v", err)
+	}
+	if validation.IsValid() {
+		t.Fatal("expected validation errors")
+	}
+	expected := []string{"missing required field proxy_host", "does not match provider", "free_tier_rate_limit must not be negative", "free_tier_rate_limit_duration is not a valid duration"}
+	for _, e := range expected {
+		found := false
+		for _, actual := range validation.Errors {
+			if strings.Contains(actual, e) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected error containing %q in %v", e, validation.Errors)
+		}
+	}
+
+	if _, _, err = Parse([]byte("not json"), samplePubkey); err == nil {
+		t.Error("expected error decoding invalid json")
+	}
+}
+
+func TestParseBadLocation(t *testing.T) {
+	raw := []byte(`{"version":"0.0.6","config":{"moniker":"m","port":"3636","location":"n/a"}}`)
+	md, validation, err := Parse(raw, samplePubkey)
+	if err != nil {
+		t.Fatalf("error parsing: %+v", err)
+	}
+	if !validation.IsValid() {
+		t.Errorf("expected valid, got errors %v", validation.Errors)
+	}
+	if validation.SchemaVersion != "0.0.0" {
+		t.Errorf("expected schema 0.0.0 got %s", validation.SchemaVersion)
+	}
+	if md.Configuration.Location != "" {
+		t.Errorf("expected location to be dropped got %s", md.Configuration.Location)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	if compareVersions("0.1.0", "0.0.6t") <= 0 {
+		t.Error("expected 0.1.0 > 0.0.6t")
+	}
+	if compareVersions("0.1", "0.1.0") != 0 {
+		t.Error("expected 0.1 == 0.1.0")
+	}
+	if compareVersions("v1.2.0", "0.9.9") <= 0 {
+		t.Error("expected v1.2.0 > 0.9.9")
+	}
+}
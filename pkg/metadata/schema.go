@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// schema lists the config fields a metadata document must (Required) or should (Recommended) carry.
+// a document is checked against the newest schema whose Version is <= the document's version
+type schema struct {
+	Version     string
+	Required    []string
+	Recommended []string
+}
+
+var schemas = []schema{
+	{
+		Version:  "0.0.0",
+		Required: []string{"moniker", "port"},
+		Recommended: []string{
+			"website", "description", "location", "proxy_host", "provider_pubkey",
+			"free_tier_rate_limit", "free_tier_rate_limit_duration",
+			"subscription_tier_rate_limit", "subscription_tier_rate_limit_duration",
+			"pay_as_you_go_tier_rate_limit", "pay_as_you_go_tier_rate_limit_duration",
+		},
+	},
+	{
+		Version: "0.1.0",
+		Required: []string{
+			"moniker", "port", "proxy_host", "provider_pubkey",
+			"free_tier_rate_limit", "free_tier_rate_limit_duration",
+			"subscription_tier_rate_limit", "subscription_tier_rate_limit_duration",
+			"pay_as_you_go_tier_rate_limit", "pay_as_you_go_tier_rate_limit_duration",
+		},
+		Recommended: []string{"website", "description", "location"},
+	},
+}
+
+func init() {
+	sort.Slice(schemas, func(i, j int) bool { return compareVersions(schemas[i].Version, schemas[j].Version) < 0 })
+}
+
+// find the schema for a document version, falling back to the oldest schema when the version can't be parsed
+func schemaFor(version string) (schema, bool) {
+	if _, ok := parseVersion(version); !ok {
+		return schemas[0], false
+	}
+	selected := schemas[0]
+	for _, s := range schemas {
+		if compareVersions(s.Version, version) <= 0 {
+			selected = s
+		}
+	}
+	return selected, true
+}
+
+// parse "1.2.3", "v1.2" or "0.0.6t" into numeric components, ignoring any non-numeric suffix
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		return nil, false
+	}
+	parts := strings.Split(version, ".")
+	result := make([]int, 0, len(parts))
+	for _, part := range parts {
+		end := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' })
+		if end == 0 {
+			return nil, false
+		}
+		if end > 0 {
+			part = part[:end]
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		result = append(result, n)
+	}
+	return result, true
+}
+
+// compare two versions, unparseable versions sort first
+func compareVersions(a, b string) int {
+	av, _ := parseVersion(a)
+	bv, _ := parseVersion(b)
+	for i := 0; i < len(av) || i < len(bv); i++ {
+		var x, y int
+		if i < len(av) {
+			x = av[i]
+		}
+		if i < len(bv) {
+			y = bv[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
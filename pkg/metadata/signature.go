@@ -0,0 +1,32 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/arkeonetwork/directory/pkg/utils"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/pkg/errors"
+)
+
+// VerifySignature checks that sig, the base64 encoded contents of a detached signature file, is the provider's
+// secp256k1 signature over the raw metadata document. signatures are 64 byte r||s over the sha256 of the
+// document, as produced by signing the file with the provider's key (e.g. `arkeod keys sign`)
+func VerifySignature(raw, sig []byte, providerPubkey, bech32PrefixAccPub string) error {
+	pk, err := utils.DecodePubkey(providerPubkey, bech32PrefixAccPub)
+	if err != nil {
+		return errors.Wrapf(err, "error decoding provider pubkey")
+	}
+	if _, ok := pk.(*secp256k1.PubKey); !ok {
+		return fmt.Errorf("unsupported pubkey type %s", pk.Type())
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+	if err != nil {
+		return errors.Wrapf(err, "error decoding signature")
+	}
+	if !pk.VerifySignature(raw, decoded) {
+		return fmt.Errorf("signature does not match provider pubkey %s", providerPubkey)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"os"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec/legacy"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+const testPrefixAccPub = "tarkeopub"
+
+func encodePubkey(t *testing.T, pk cryptotypes.PubKey) string {
+	pubkey, err := bech32.ConvertAndEncode(testPrefixAccPub, legacy.Cdc.MustMarshal(pk))
+	if err != nil {
+		t.Fatalf("error encoding pubkey: %+v", err)
+	}
+	return pubkey
+}
+
+func TestVerifySignature(t *testing.T) {
+	raw, err := os.ReadFile("../../docs/sample-metadata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := secp256k1.GenPrivKey()
+	pubkey := encodePubkey(t, key.PubKey())
+	sig, err := key.Sign(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+
+	if err = VerifySignature(raw, encoded, pubkey, testPrefixAccPub); err != nil {
+		t.Errorf("expected signature to verify: %+v", err)
+	}
+	if err = VerifySignature(append(raw, ' '), encoded, pubkey, testPrefixAccPub); err == nil {
+		t.Error("expected modified document to fail verification")
+	}
+	other := encodePubkey(t, secp256k1.GenPrivKey().PubKey())
+	if err = VerifySignature(raw, encoded, other, testPrefixAccPub); err == nil {
+		t.Error("expected another provider's pubkey to fail verification")
+	}
+	if err = VerifySignature(raw, encoded, pubkey, "arkeopub"); err == nil {
+		t.Error("expected prefix mismatch to fail")
+	}
+	if err = VerifySignature(raw, []byte("not base64!"), pubkey, testPrefixAccPub); err == nil {
+		t.Error("expected malformed signature to fail")
+	}
+	edPubkey := encodePubkey(t, ed25519.GenPrivKey().PubKey())
+	if err = VerifySignature(raw, encoded, edPubkey, testPrefixAccPub); err == nil {
+		t.Error("expected non secp256k1 pubkey to fail")
+	}
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(HTTPMiddleware)
+	router.HandleFunc("/provider/{pubkey}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	for _, pubkey := range []string{"a", "b"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/provider/"+pubkey, nil))
+	}
+	if n := testutil.ToFloat64(HTTPRequests.WithLabelValues("/provider/{pubkey}", "404")); n != 2 {
+		t.Errorf("expected 2 requests recorded against the route template, got %v", n)
+	}
+}
+
+func TestSetHeights(t *testing.T) {
+	SetHeights(90, 100)
+	if lag := testutil.ToFloat64(Lag); lag != 10 {
+		t.Errorf("expected lag 10, got %v", lag)
+	}
+	SetHeights(100, 90)
+	if lag := testutil.ToFloat64(Lag); lag != 0 {
+		t.Errorf("expected lag 0 when indexed is ahead of the reported tip, got %v", lag)
+	}
+	// a new tip leaves the indexed height as last reported
+	SetChainTip(120)
+	if lag := testutil.ToFloat64(Lag); lag != 20 {
+		t.Errorf("expected lag 20 behind the new tip, got %v", lag)
+	}
+}
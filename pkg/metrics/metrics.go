@@ -0,0 +1,131 @@
+// Package metrics defines the prometheus metrics exported by the indexer and api on /metrics.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "directory"
+
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// indexer
+var (
+	IndexedHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "indexed_height",
+		Help:      "Latest block height stored by the indexer.",
+	})
+	ChainTip = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chain_tip",
+		Help:      "Latest block height reported by the chain.",
+	})
+	Lag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "indexer_lag_blocks",
+		Help:      "Blocks between the chain tip and the latest indexed height.",
+	})
+	EventsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_processed_total",
+		Help:      "Chain events handled, by event type.",
+	}, []string{"type"})
+	EventsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_failed_total",
+		Help:      "Chain events that failed to decode or handle, by event type and stage.",
+	}, []string{"type", "stage"})
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Tendermint rpc call latency, by method and status.",
+		Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"method", "status"})
+	GapFillBlocks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gap_fill_blocks_total",
+		Help:      "Historical blocks processed by the gap filler, by status.",
+	}, []string{"status"})
+	MetadataFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "metadata_fetches_total",
+		Help:      "Provider metadata refreshes, by outcome.",
+	}, []string{"outcome"})
+	ReconcileRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_runs_total",
+		Help:      "Reconciliations against the arkeo api, by status.",
+	}, []string{"status"})
+	ReconcileMismatches = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconcile_mismatches",
+		Help:      "Fields differing from the chain in the last reconciliation, by entity and field.",
+	}, []string{"entity", "field"})
+)
+
+// the heights last reported, lag is recomputed from both when either changes
+var (
+	heightsMu     sync.Mutex
+	indexedHeight int64
+	chainTip      int64
+)
+
+// SetHeights updates the indexed height, chain tip and lag gauges
+func SetHeights(indexed, tip int64) {
+	heightsMu.Lock()
+	defer heightsMu.Unlock()
+	indexedHeight, chainTip = indexed, tip
+	setHeights(indexed, tip)
+}
+
+// SetChainTip updates the chain tip and the lag behind it of the indexed height last reported
+func SetChainTip(tip int64) {
+	heightsMu.Lock()
+	defer heightsMu.Unlock()
+	chainTip = tip
+	setHeights(indexedHeight, tip)
+}
+
+func setHeights(indexed, tip int64) {
+	IndexedHeight.Set(float64(indexed))
+	ChainTip.Set(float64(tip))
+	lag := tip - indexed
+	if lag < 0 {
+		lag = 0
+	}
+	Lag.Set(float64(lag))
+}
+
+// ObserveEvent counts an event of eventType as processed, or failed at stage when err is set
+func ObserveEvent(eventType, stage string, err error) {
+	if err != nil {
+		EventsFailed.WithLabelValues(eventType, stage).Inc()
+		return
+	}
+	EventsProcessed.WithLabelValues(eventType).Inc()
+}
+
+// ObserveRPC records the latency of an rpc call started at start
+func ObserveRPC(method string, start time.Time, err error) {
+	RPCDuration.WithLabelValues(method, status(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveReconcile counts a reconciliation run
+func ObserveReconcile(err error) {
+	ReconcileRuns.WithLabelValues(status(err)).Inc()
+}
+
+func status(err error) string {
+	if err != nil {
+		return StatusError
+	}
+	return StatusOK
+}
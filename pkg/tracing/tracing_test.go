@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var handlerSpan trace.SpanContext
+	router := mux.NewRouter()
+	router.Use(HTTPMiddleware)
+	router.HandleFunc("/provider/{pubkey}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := Start(r.Context(), "db.FindProvider")
+		handlerSpan = span.SpanContext()
+		span.End()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/provider/abc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	server := spans[1]
+	if server.Name() != "GET /provider/{pubkey}" {
+		t.Errorf("expected span named by route template, got %s", server.Name())
+	}
+	if server.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected propagated trace id, got %s", server.SpanContext().TraceID())
+	}
+	if server.Status().Code != codes.Error {
+		t.Errorf("expected 500 to mark the span failed")
+	}
+	if spans[0].Parent().SpanID() != server.SpanContext().SpanID() || handlerSpan.TraceID() != server.SpanContext().TraceID() {
+		t.Errorf("expected handler span to be a child of the request span")
+	}
+}
+
+func TestEnd(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := Start(context.Background(), "ok")
+	End(span, nil)
+	_, span = Start(context.Background(), "failed")
+	End(span, errors.New("boom"))
+
+	spans := recorder.Ended()
+	if spans[0].Status().Code == codes.Error {
+		t.Errorf("expected ok span not to be failed")
+	}
+	if spans[1].Status().Code != codes.Error || spans[1].Status().Description != "boom" {
+		t.Errorf("expected failed span with description, got %+v", spans[1].Status())
+	}
+}
+
+func TestInitUnknownExporter(t *testing.T) {
+	if _, err := Init(Config{Exporter: "zipkin"}); err == nil {
+		t.Errorf("expected error for unknown exporter")
+	}
+}
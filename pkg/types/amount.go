@@ -0,0 +1,251 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgtype"
+	"github.com/mitchellh/mapstructure"
+)
+
+// DefaultDenom is the base denom of the arkeo token. Amounts are stored in it and amounts given without a denom
+// are in it
+const DefaultDenom = "uarkeo"
+
+// cosmos coin strings such as 100uarkeo, the denom is optional
+var coinRegexp = regexp.MustCompile(`^(-?[0-9]+)([a-zA-Z][a-zA-Z0-9/:._-]{2,127})?$`)
+
+// swagger:strfmt amount
+//
+// Amount is an arbitrary precision integer amount of a denom. The zero value is unset, as for an event attribute
+// that wasn't present, and is zero in arithmetic. Like cosmos coins, arithmetic and comparisons panic when denoms
+// differ
+type Amount struct {
+	amount *big.Int
+	denom  string
+}
+
+func NewAmount(amount int64, denom string) Amount {
+	return Amount{amount: big.NewInt(amount), denom: denom}
+}
+
+func NewAmountFromBigInt(amount *big.Int, denom string) Amount {
+	return Amount{amount: new(big.Int).Set(amount), denom: denom}
+}
+
+// ParseAmount parses a coin string such as 100uarkeo, or a bare integer in DefaultDenom
+func ParseAmount(s string) (Amount, error) {
+	m := coinRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Amount{}, fmt.Errorf("invalid amount %q", s)
+	}
+	i, ok := new(big.Int).SetString(m[1], 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid amount %q", s)
+	}
+	denom := m[2]
+	if denom == "" {
+		denom = DefaultDenom
+	}
+	return Amount{amount: i, denom: denom}, nil
+}
+
+// IsSet is false for the zero value
+func (a Amount) IsSet() bool {
+	return a.amount != nil
+}
+
+// OrZero is the amount, or zero in its denom when unset, for columns an absent attribute is stored as 0 in
+func (a Amount) OrZero() Amount {
+	if a.IsSet() {
+		return a
+	}
+	return NewAmount(0, a.Denom())
+}
+
+func (a Amount) Denom() string {
+	if a.denom == "" {
+		return DefaultDenom
+	}
+	return a.denom
+}
+
+// BigInt returns a copy of the amount, zero when unset
+func (a Amount) BigInt() *big.Int {
+	if a.amount == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.amount)
+}
+
+func (a Amount) Sign() int {
+	if a.amount == nil {
+		return 0
+	}
+	return a.amount.Sign()
+}
+
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+func (a Amount) Add(b Amount) Amount {
+	a.mustMatch(b)
+	return Amount{amount: new(big.Int).Add(a.BigInt(), b.BigInt()), denom: a.Denom()}
+}
+
+func (a Amount) Sub(b Amount) Amount {
+	a.mustMatch(b)
+	return Amount{amount: new(big.Int).Sub(a.BigInt(), b.BigInt()), denom: a.Denom()}
+}
+
+func (a Amount) MulInt64(n int64) Amount {
+	return Amount{amount: new(big.Int).Mul(a.BigInt(), big.NewInt(n)), denom: a.Denom()}
+}
+
+// Cmp returns -1, 0 or 1 as a is less than, equal to or greater than b
+func (a Amount) Cmp(b Amount) int {
+	a.mustMatch(b)
+	return a.BigInt().Cmp(b.BigInt())
+}
+
+// Equal is true for amounts of the same denom and value, unlike Cmp it doesn't panic when denoms differ
+func (a Amount) Equal(b Amount) bool {
+	return a.Denom() == b.Denom() && a.BigInt().Cmp(b.BigInt()) == 0
+}
+
+// Float64 approximates the amount, for scoring rather than accounting
+func (a Amount) Float64() float64 {
+	f, _ := new(big.Float).SetInt(a.BigInt()).Float64()
+	return f
+}
+
+func (a Amount) mustMatch(b Amount) {
+	if a.Denom() != b.Denom() {
+		panic(fmt.Sprintf("amount denoms differ: %s and %s", a.Denom(), b.Denom()))
+	}
+}
+
+// String formats the amount as a coin string, 100uarkeo
+func (a Amount) String() string {
+	return a.BigInt().String() + a.Denom()
+}
+
+// amounts marshal as coin strings so clients never lose precision, unset amounts as null
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if !a.IsSet() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts coin strings and, as the cosmos rest gateway encodes integers either way, plain numbers
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		*a = Amount{}
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*a = Amount{}
+			return nil
+		}
+	}
+	parsed, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Scan reads a numeric or integer column holding an amount in DefaultDenom
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = Amount{}
+		return nil
+	case int64:
+		*a = NewAmount(v, DefaultDenom)
+		return nil
+	case []byte:
+		return a.scanNumeric(string(v))
+	case string:
+		return a.scanNumeric(v)
+	default:
+		return fmt.Errorf("cannot scan %T into amount", src)
+	}
+}
+
+// numerics arrive in text form, possibly with an exponent (100e0)
+func (a *Amount) scanNumeric(s string) error {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return fmt.Errorf("cannot scan %q into amount", s)
+	}
+	if !r.IsInt() {
+		return fmt.Errorf("amount %q is not an integer", s)
+	}
+	*a = Amount{amount: new(big.Int).Set(r.Num()), denom: DefaultDenom}
+	return nil
+}
+
+// Value stores the amount as a plain integer, only DefaultDenom amounts may be stored
+func (a Amount) Value() (driver.Value, error) {
+	if !a.IsSet() {
+		return nil, nil
+	}
+	if a.Denom() != DefaultDenom {
+		return nil, fmt.Errorf("cannot store amount %s, amounts are stored in %s", a, DefaultDenom)
+	}
+	return a.amount.String(), nil
+}
+
+// EncodeText lets pgx send amounts as text query parameters, which postgres casts to numeric or bigint columns
+func (a Amount) EncodeText(_ *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	v, err := a.Value()
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return append(buf, v.(string)...), nil
+}
+
+var amountType = reflect.TypeOf(Amount{})
+
+// AmountDecodeHook decodes event attributes, coin strings or numbers, into Amount fields
+func AmountDecodeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != amountType {
+		return data, nil
+	}
+	switch v := data.(type) {
+	case string:
+		if v == "" {
+			return Amount{}, nil
+		}
+		return ParseAmount(v)
+	case int:
+		return NewAmount(int64(v), DefaultDenom), nil
+	case int64:
+		return NewAmount(v, DefaultDenom), nil
+	case float64:
+		f := new(big.Float).SetFloat64(v)
+		if !f.IsInt() {
+			return nil, fmt.Errorf("amount %v is not an integer", v)
+		}
+		i, _ := f.Int(nil)
+		return Amount{amount: i, denom: DefaultDenom}, nil
+	default:
+		return data, nil
+	}
+}
+
+var _ mapstructure.DecodeHookFuncType = AmountDecodeHook
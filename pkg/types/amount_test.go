@@ -0,0 +1,144 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+func TestParseAmount(t *testing.T) {
+	for input, expected := range map[string]string{
+		"100uarkeo":                     "100uarkeo",
+		"100":                           "100uarkeo",
+		"-5uarkeo":                      "-5uarkeo",
+		"12ibc/ABC123":                  "12ibc/ABC123",
+		"99999999999999999999999uarkeo": "99999999999999999999999uarkeo",
+	} {
+		a, err := ParseAmount(input)
+		if err != nil {
+			t.Errorf("error parsing %s: %+v", input, err)
+			continue
+		}
+		if a.String() != expected {
+			t.Errorf("expected %s got %s", expected, a)
+		}
+	}
+	for _, input := range []string{"", "uarkeo", "1.5uarkeo", "10 uarkeo", "1u"} {
+		if _, err := ParseAmount(input); err == nil {
+			t.Errorf("expected error parsing %q", input)
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	max := NewAmount(1<<62, DefaultDenom)
+	sum := max.Add(max).Add(max).Add(max)
+	expected := new(big.Int).Lsh(big.NewInt(1), 64)
+	if sum.BigInt().Cmp(expected) != 0 {
+		t.Errorf("expected %s got %s", expected, sum.BigInt())
+	}
+	if max.Cmp(sum) >= 0 || sum.Sub(max).Cmp(max.MulInt64(3)) != 0 {
+		t.Errorf("unexpected comparison of %s and %s", max, sum)
+	}
+	var unset Amount
+	if unset.IsSet() || !unset.IsZero() || unset.Add(max).Cmp(max) != 0 {
+		t.Errorf("expected unset amount to add as zero")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic adding different denoms")
+		}
+	}()
+	max.Add(NewAmount(1, "uatom"))
+}
+
+func TestAmountJSON(t *testing.T) {
+	v := struct {
+		Set   Amount
+		Unset Amount
+	}{Set: NewAmount(42, DefaultDenom)}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("error marshalling: %+v", err)
+	}
+	if string(b) != `{"Set":"42uarkeo","Unset":null}` {
+		t.Errorf("unexpected json %s", b)
+	}
+
+	var parsed struct{ A, B, C Amount }
+	if err = json.Unmarshal([]byte(`{"A":"7uatom","B":12,"C":null}`), &parsed); err != nil {
+		t.Fatalf("error unmarshalling: %+v", err)
+	}
+	if parsed.A.String() != "7uatom" || parsed.B.String() != "12uarkeo" || parsed.C.IsSet() {
+		t.Errorf("unexpected amounts %s %s %v", parsed.A, parsed.B, parsed.C.IsSet())
+	}
+}
+
+func TestAmountScan(t *testing.T) {
+	for src, expected := range map[interface{}]string{
+		int64(5):                       "5uarkeo",
+		"1e2":                          "100uarkeo",
+		"123456789012345678901234e0":   "123456789012345678901234uarkeo",
+		"100.000":                      "100uarkeo",
+		string([]byte("1234567890e1")): "12345678900uarkeo",
+	} {
+		var a Amount
+		if err := a.Scan(src); err != nil {
+			t.Errorf("error scanning %v: %+v", src, err)
+			continue
+		}
+		if a.String() != expected {
+			t.Errorf("expected %s got %s", expected, a)
+		}
+	}
+	var a Amount
+	if err := a.Scan("15e-1"); err == nil {
+		t.Errorf("expected error scanning a fraction")
+	}
+	if _, err := NewAmount(1, "uatom").Value(); err == nil {
+		t.Errorf("expected error storing a non default denom")
+	}
+	if v, err := NewAmount(10, DefaultDenom).Value(); err != nil || v != "10" {
+		t.Errorf("expected stored value 10 got %v %v", v, err)
+	}
+	// unset amounts are stored as null unless zeroed
+	if v, err := (Amount{}).Value(); err != nil || v != nil {
+		t.Errorf("expected unset amount stored as null got %v %v", v, err)
+	}
+	if v, err := (Amount{}).OrZero().Value(); err != nil || v != "0" {
+		t.Errorf("expected zeroed amount stored as 0 got %v %v", v, err)
+	}
+	if a := NewAmount(7, DefaultDenom).OrZero(); a.String() != "7uarkeo" {
+		t.Errorf("expected a set amount unchanged got %s", a)
+	}
+}
+
+func TestAmountDecodeHook(t *testing.T) {
+	evt := struct {
+		Paid    Amount `mapstructure:"paid"`
+		Reserve Amount `mapstructure:"reserve"`
+		Missing Amount `mapstructure:"missing"`
+		Height  int64  `mapstructure:"height"`
+	}{}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       AmountDecodeHook,
+		WeaklyTypedInput: true,
+		Result:           &evt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = decoder.Decode(map[string]string{"paid": "100uarkeo", "reserve": "20", "height": "7"}); err != nil {
+		t.Fatalf("error decoding: %+v", err)
+	}
+	if evt.Paid.String() != "100uarkeo" || evt.Reserve.String() != "20uarkeo" || evt.Missing.IsSet() || evt.Height != 7 {
+		t.Errorf("unexpected event %+v", evt)
+	}
+	if _, err = AmountDecodeHook(reflect.TypeOf(""), amountType, "bad"); err == nil {
+		t.Errorf("expected error decoding a malformed amount")
+	}
+}
@@ -1,12 +1,30 @@
 package types
 
+// position of an event within its tx, the message it was emitted by and its place among all the tx's events. a tx
+// may emit several events of a type so events are keyed by txid and position. end block events have none
+type TxEventIndex struct {
+	MsgIndex   int `mapstructure:"msgIndex"`
+	EventIndex int `mapstructure:"eventIndex"`
+}
+
+// the account behind an event's message and the tx it was sent in. Grantee is the authz grantee when the message was
+// executed through MsgExec, FeePayer and Memo are the tx's. end block events have none
+type TxSigner struct {
+	Signer   string `mapstructure:"msgSigner"`
+	Grantee  string `mapstructure:"msgGrantee"`
+	FeePayer string `mapstructure:"txFeePayer"`
+	Memo     string `mapstructure:"txMemo"`
+}
+
 type BondProviderEvent struct {
+	TxEventIndex `mapstructure:",squash"`
+	TxSigner     `mapstructure:",squash"`
 	Pubkey       string `mapstructure:"provider"`
 	Chain        string `mapstructure:"chain"`
 	Height       int64  `mapstructure:"height"`
 	TxID         string `mapstructure:"hash"`
-	BondRelative string `mapstructure:"bond_rel"`
-	BondAbsolute string `mapstructure:"bond_abs"`
+	BondRelative Amount `mapstructure:"bond_rel"`
+	BondAbsolute Amount `mapstructure:"bond_abs"`
 }
 
 type ContractType string
@@ -17,6 +35,8 @@ var (
 )
 
 type BaseContractEvent struct {
+	TxEventIndex   `mapstructure:",squash"`
+	TxSigner       `mapstructure:",squash"`
 	ProviderPubkey string `mapstructure:"provider"`
 	Chain          string `mapstructure:"chain"`
 	ClientPubkey   string `mapstructure:"client"`
@@ -24,6 +44,8 @@ type BaseContractEvent struct {
 	TxID           string `mapstructure:"hash"`
 	Height         int64  `mapstructure:"height"`
 	EventHeight    int64  `mapstructure:"eventHeight"`
+	// the chain's identifier of the contract, 0 for events which predate it
+	ContractID uint64 `mapstructure:"contract_id"`
 }
 
 // get the delegate pubkey falling back to client pubkey if undefined
@@ -38,15 +60,15 @@ type OpenContractEvent struct {
 	BaseContractEvent `mapstructure:",squash"`
 	Duration          int64        `mapstructure:"duration"`
 	ContractType      ContractType `mapstructure:"type"`
-	Rate              int64        `mapstructure:"rate"`
-	OpenCost          int64        `mapstructure:"open_cost"`
+	Rate              Amount       `mapstructure:"rate"`
+	OpenCost          Amount       `mapstructure:"open_cost"`
 }
 
 type ContractSettlementEvent struct {
 	BaseContractEvent `mapstructure:",squash"`
 	Nonce             string `mapstructure:"nonce"`
-	Paid              string `mapstructure:"paid"`
-	Reserve           string `mapstructure:"reserve"`
+	Paid              Amount `mapstructure:"paid"`
+	Reserve           Amount `mapstructure:"reserve"`
 }
 
 type CloseContractEvent struct {
@@ -61,7 +83,7 @@ type ValidatorPayoutEvent struct {
 	Validator string `mapstructure:"validator"`
 	Height    int64  `mapstructure:"height"`
 	TxID      string `mapstructure:"hash"`
-	Paid      int64  `mapstructure:"paid"`
+	Paid      Amount `mapstructure:"paid"`
 }
 
 type ProviderStatus string
@@ -71,8 +93,18 @@ var (
 	ProviderStatusOffline ProviderStatus = "Offline"
 )
 
+type MetadataFetchStatus string
+
+var (
+	MetadataFetchStatusPending MetadataFetchStatus = "Pending"
+	MetadataFetchStatusFetched MetadataFetchStatus = "Fetched"
+	MetadataFetchStatusFailed  MetadataFetchStatus = "Failed"
+)
+
 type ModProviderEvent struct {
-	Pubkey              string         `mapstructure:"pubkey"` // TODO provider
+	TxEventIndex        `mapstructure:",squash"`
+	TxSigner            `mapstructure:",squash"`
+	Pubkey              string         `mapstructure:"pubkey"` // v1 emits the provider as pubkey, see indexer.eventDecoders
 	Chain               string         `mapstructure:"chain"`
 	Height              int64          `mapstructure:"height"`
 	TxID                string         `mapstructure:"hash"`
@@ -81,8 +113,8 @@ type ModProviderEvent struct {
 	Status              ProviderStatus `mapstructure:"status"`
 	MinContractDuration int64          `mapstructure:"min_contract_duration"`
 	MaxContractDuration int64          `mapstructure:"max_contract_duration"`
-	SubscriptionRate    int64          `mapstructure:"subscription_rate"`
-	PayAsYouGoRate      int64          `mapstructure:"pay-as-you-go_rate"`
+	SubscriptionRate    Amount         `mapstructure:"subscription_rate"`
+	PayAsYouGoRate      Amount         `mapstructure:"pay-as-you-go_rate"`
 }
 
 type Coordinates struct {
@@ -97,6 +129,9 @@ var (
 	ProviderSortKeyAge           ProviderSortKey = "age"
 	ProviderSortKeyContractCount ProviderSortKey = "contract_count"
 	ProviderSortKeyAmountPaid    ProviderSortKey = "amount_paid"
+	ProviderSortKeyUptime        ProviderSortKey = "uptime"
+	ProviderSortKeyLatency       ProviderSortKey = "latency"
+	ProviderSortKeyReputation    ProviderSortKey = "reputation"
 )
 
 type ProviderSearchParams struct {
@@ -118,17 +153,40 @@ type ProviderSearchParams struct {
 	IsMinSubscribeRateLimitSet bool
 	MinOpenContracts           int64
 	IsMinOpenContractsSet      bool
+	Verified                   bool
+	IsVerifiedSet              bool
+	MinUptime                  float64
+	IsMinUptimeSet             bool
+	MaxLatency                 int64
+	IsMaxLatencySet            bool
+}
+
+// tier of service a client is looking for, free is rate limited use without a contract
+type ServiceTier string
+
+var (
+	ServiceTierNone         ServiceTier = ""
+	ServiceTierFree         ServiceTier = "free"
+	ServiceTierSubscription ServiceTier = "subscription"
+	ServiceTierPayAsYouGo   ServiceTier = "paygo"
+)
+
+type ProviderRecommendParams struct {
+	Chain            string
+	Tier             ServiceTier
+	Coordinates      Coordinates
+	IsCoordinatesSet bool
 }
 
 // swagger:model ArkeoStats
 type ArkeoStats struct {
-	ContractsOpen           int64 `db:"open_contracts"`
-	ContractsTotal          int64 `db:"total_contracts"`
-	ContractsMedianDuration int64 `db:"median_open_contract_length"`
-	ContractsMedianRate     int64 `db:"median_open_contract_rate"`
-	ProviderCount           int64 `db:"total_online_providers"`
-	QueryCount              int64 `db:"total_queries"`
-	TotalIncome             int64 `db:"total_paid"`
+	ContractsOpen           int64  `db:"open_contracts"`
+	ContractsTotal          int64  `db:"total_contracts"`
+	ContractsMedianDuration int64  `db:"median_open_contract_length"`
+	ContractsMedianRate     int64  `db:"median_open_contract_rate"`
+	ProviderCount           int64  `db:"total_online_providers"`
+	QueryCount              int64  `db:"total_queries"`
+	TotalIncome             Amount `db:"total_paid"`
 	// TODO: in the future we can add more complicated structure
 	// ContractsMedianRatePayPer       int64
 	// ContractsMedianRateSubscription int64
@@ -141,6 +199,18 @@ type ChainStats struct {
 	ProviderCount      int64
 	QueryCount         int64
 	QueryCountLastDay  int64
-	TotalIncome        int64
-	TotalIncomeLastDay int64
+	TotalIncome        Amount
+	TotalIncomeLastDay Amount
+}
+
+// outcome of validating a provider metadata document against its schema. documents with errors fail
+// hard checks and are excluded from search, warnings are informational
+type MetadataValidation struct {
+	SchemaVersion string
+	Warnings      []string
+	Errors        []string
+}
+
+func (v MetadataValidation) IsValid() bool {
+	return len(v.Errors) == 0
 }
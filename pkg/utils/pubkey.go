@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec/legacy"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/pkg/errors"
+)
+
+// DecodePubkey decodes a bech32 account pubkey as it appears in arkeo events (amino encoded key bytes),
+// requiring the given human readable prefix
+func DecodePubkey(pubkey, bech32PrefixAccPub string) (cryptotypes.PubKey, error) {
+	hrp, bz, err := bech32.DecodeAndConvert(pubkey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error decoding bech32 pubkey %s", pubkey)
+	}
+	if hrp != bech32PrefixAccPub {
+		return nil, fmt.Errorf("pubkey %s has prefix %s, expected %s", pubkey, hrp, bech32PrefixAccPub)
+	}
+	pk, err := legacy.PubKeyFromBytes(bz)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error unmarshalling pubkey %s", pubkey)
+	}
+	return pk, nil
+}
+
+// PubkeyToAddress derives the bech32 account address of a bech32 account pubkey
+func PubkeyToAddress(pubkey, bech32PrefixAccPub, bech32PrefixAccAddr string) (string, error) {
+	pk, err := DecodePubkey(pubkey, bech32PrefixAccPub)
+	if err != nil {
+		return "", err
+	}
+	addr, err := bech32.ConvertAndEncode(bech32PrefixAccAddr, pk.Address())
+	if err != nil {
+		return "", errors.Wrapf(err, "error encoding address for %s", pubkey)
+	}
+	return addr, nil
+}
+
+// ValoperToAccAddress converts a validator operator address to the account address sharing its bytes
+func ValoperToAccAddress(valoper, bech32PrefixAccAddr string) (string, error) {
+	_, bz, err := bech32.DecodeAndConvert(valoper)
+	if err != nil {
+		return "", errors.Wrapf(err, "error decoding validator address %s", valoper)
+	}
+	addr, err := bech32.ConvertAndEncode(bech32PrefixAccAddr, bz)
+	if err != nil {
+		return "", errors.Wrapf(err, "error encoding address for %s", valoper)
+	}
+	return addr, nil
+}
+
+// AccountKey is an account identified by a bech32 pubkey or address. Pubkey is empty when only the address was given
+type AccountKey struct {
+	Pubkey  string
+	Address string
+}
+
+// ParseAccountKey accepts either a bech32 account pubkey or account address with the given prefixes, deriving the
+// address from a pubkey
+func ParseAccountKey(key, bech32PrefixAccPub, bech32PrefixAccAddr string) (AccountKey, error) {
+	hrp, bz, err := bech32.DecodeAndConvert(key)
+	if err != nil {
+		return AccountKey{}, errors.Wrapf(err, "error decoding bech32 key %s", key)
+	}
+	switch hrp {
+	case bech32PrefixAccPub:
+		addr, err := PubkeyToAddress(key, bech32PrefixAccPub, bech32PrefixAccAddr)
+		if err != nil {
+			return AccountKey{}, err
+		}
+		return AccountKey{Pubkey: key, Address: addr}, nil
+	case bech32PrefixAccAddr:
+		if err = sdk.VerifyAddressFormat(bz); err != nil {
+			return AccountKey{}, errors.Wrapf(err, "invalid address %s", key)
+		}
+		return AccountKey{Address: key}, nil
+	default:
+		return AccountKey{}, fmt.Errorf("key %s has prefix %s, expected %s or %s", key, hrp, bech32PrefixAccPub, bech32PrefixAccAddr)
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec/legacy"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+func TestPubkeyToAddress(t *testing.T) {
+	pk := secp256k1.GenPrivKey().PubKey()
+	pubkey, err := bech32.ConvertAndEncode("tarkeopub", legacy.Cdc.MustMarshal(pk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := bech32.ConvertAndEncode("tarkeo", pk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := PubkeyToAddress(pubkey, "tarkeopub", "tarkeo")
+	if err != nil {
+		t.Fatalf("error deriving address: %+v", err)
+	}
+	if addr != expected {
+		t.Errorf("expected %s got %s", expected, addr)
+	}
+	if _, err = PubkeyToAddress(pubkey, "arkeopub", "arkeo"); err == nil {
+		t.Error("expected prefix mismatch to fail")
+	}
+	if _, err = PubkeyToAddress("tarkeopub1notbech32", "tarkeopub", "tarkeo"); err == nil {
+		t.Error("expected malformed pubkey to fail")
+	}
+
+	valoper, err := bech32.ConvertAndEncode("tarkeovaloper", pk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr, err = ValoperToAccAddress(valoper, "tarkeo"); err != nil || addr != expected {
+		t.Errorf("expected %s got %s %v", expected, addr, err)
+	}
+}
+
+func TestParseAccountKey(t *testing.T) {
+	pk := secp256k1.GenPrivKey().PubKey()
+	pubkey, err := bech32.ConvertAndEncode("tarkeopub", legacy.Cdc.MustMarshal(pk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := bech32.ConvertAndEncode("tarkeo", pk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ParseAccountKey(pubkey, "tarkeopub", "tarkeo")
+	if err != nil || key.Pubkey != pubkey || key.Address != addr {
+		t.Errorf("expected pubkey %s and address %s got %+v %v", pubkey, addr, key, err)
+	}
+	key, err = ParseAccountKey(addr, "tarkeopub", "tarkeo")
+	if err != nil || key.Pubkey != "" || key.Address != addr {
+		t.Errorf("expected address only %s got %+v %v", addr, key, err)
+	}
+	for _, bad := range []string{"", "tarkeo1notbech32", addr + "x", pubkey[:len(pubkey)-1]} {
+		if _, err = ParseAccountKey(bad, "tarkeopub", "tarkeo"); err == nil {
+			t.Errorf("expected error parsing %q", bad)
+		}
+	}
+	if _, err = ParseAccountKey(addr, "arkeopub", "arkeo"); err == nil {
+		t.Error("expected prefix mismatch to fail")
+	}
+}